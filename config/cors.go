@@ -24,6 +24,12 @@ var AllowedHeaders = []string{
 	"Access-Control-Allow-Origin",
 	"Bearer",
 	"X-Requested-With",
+	"If-Match",
+}
+
+// header respon yang boleh dibaca javascript di browser
+var ExposedHeaders = []string{
+	"ETag",
 }
 
 func SetAccessControlHeaders(w http.ResponseWriter, r *http.Request) bool {
@@ -42,8 +48,9 @@ func SetAccessControlHeaders(w http.ResponseWriter, r *http.Request) bool {
 	}
 
 	w.Header().Set("Access-Control-Allow-Credentials", "true")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, PUT, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, PUT, PATCH, DELETE, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", strings.Join(AllowedHeaders, ", "))
+	w.Header().Set("Access-Control-Expose-Headers", strings.Join(ExposedHeaders, ", "))
 	w.Header().Set("Access-Control-Allow-Origin", origin)
 
 	if r.Method == http.MethodOptions {
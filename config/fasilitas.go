@@ -0,0 +1,14 @@
+package config
+
+import "github.com/gocroot/model"
+
+// DefaultFasilitas diisikan ke koleksi fasilitas jika koleksi masih kosong
+var DefaultFasilitas = []model.FasilitasTag{
+	{Kode: "atap", Nama: "Beratap", Alias: []string{"covered", "teduh", "indoor", "basement"}},
+	{Kode: "cctv", Nama: "CCTV", Alias: []string{"kamera"}},
+	{Kode: "toilet", Nama: "Toilet", Alias: []string{"wc", "kamar mandi"}},
+	{Kode: "motor_saja", Nama: "Khusus motor", Alias: []string{"motorcycle only", "motor saja", "hanya motor", "khusus motor"}},
+	{Kode: "satpam", Nama: "Satpam", Alias: []string{"security", "penjaga", "keamanan"}},
+	{Kode: "mushola", Nama: "Mushola", Alias: []string{"musholla", "musala", "masjid"}},
+	{Kode: "24_jam", Nama: "24 jam", Alias: []string{"24jam", "24 hours"}},
+}
@@ -0,0 +1,21 @@
+package config
+
+// prefix URL raw repo parkirgratis/filegambar, path hasil upload ditempel di belakangnya
+var RepoGambarURL string = "https://raw.githubusercontent.com/parkirgratis/filegambar/main/"
+
+// sisi terpanjang varian hasil proses upload dalam piksel, gambar yang lebih kecil tidak diperbesar
+var GambarWebMax int = 1600
+
+var GambarThumbMax int = 320
+
+// kualitas JPEG varian web dan thumb (1-100). Varian selalu JPEG, output WebP belum didukung karena belum ada encoder WebP tanpa cgo
+var GambarKualitas int = 82
+
+// batas lebar x tinggi gambar yang mau didecode supaya file kecil dengan dimensi raksasa tidak menghabiskan memori
+var GambarMaxPiksel int = 50_000_000
+
+// ukuran maksimal file upload gambar dalam byte
+var GambarMaxSize int64 = 10 << 20
+
+// tipe hasil sniffing http.DetectContentType yang diterima, bukan header Content-Type dari client
+var GambarMIME = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/gocroot/helper/atdb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var indexOnce sync.Once
+
+// EnsureIndexes dijalankan sekali per instance, menyiapkan field GeoJSON dan index yang dibutuhkan query
+func EnsureIndexes() {
+	indexOnce.Do(func() {
+		if ErrorMongoconn != nil {
+			return
+		}
+		//data lama hanya punya lon/lat, isi field location supaya bisa di-index
+		filter := bson.M{
+			"location": bson.M{"$exists": false},
+			"lon":      bson.M{"$exists": true},
+			"lat":      bson.M{"$exists": true},
+		}
+		if _, err := atdb.UpdateManyDoc(Mongoconn, "tempat", filter, TempatLocationPipeline); err != nil {
+			log.Println("EnsureIndexes location:", err)
+		}
+		index := mongo.IndexModel{Keys: bson.D{{Key: "location", Value: "2dsphere"}}}
+		if _, err := atdb.CreateIndex(Mongoconn, "tempat", index); err != nil {
+			log.Println("EnsureIndexes tempat 2dsphere:", err)
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "lon", Value: 1}, {Key: "lat", Value: 1}}}
+		if _, err := atdb.CreateIndex(Mongoconn, "tempat", index); err != nil {
+			log.Println("EnsureIndexes tempat lon/lat:", err)
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "nama_tempat", Value: 1}, {Key: "_id", Value: 1}}}
+		if _, err := atdb.CreateIndex(Mongoconn, "tempat", index); err != nil {
+			log.Println("EnsureIndexes tempat nama_tempat:", err)
+		}
+		//default_language none supaya kata bahasa Indonesia tidak di-stem dengan aturan bahasa Inggris
+		index = mongo.IndexModel{
+			Keys: bson.D{{Key: "nama_tempat", Value: "text"}, {Key: "lokasi", Value: "text"}, {Key: "fasilitas", Value: "text"}},
+			Options: options.Index().
+				SetName("tempat_text").
+				SetDefaultLanguage("none").
+				SetWeights(bson.D{{Key: "nama_tempat", Value: 10}, {Key: "lokasi", Value: 5}, {Key: "fasilitas", Value: 2}}),
+		}
+		if _, err := atdb.CreateIndex(Mongoconn, "tempat", index); err != nil {
+			log.Println("EnsureIndexes tempat text:", err)
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "fasilitas_tags", Value: 1}}}
+		if _, err := atdb.CreateIndex(Mongoconn, "tempat", index); err != nil {
+			log.Println("EnsureIndexes tempat fasilitas_tags:", err)
+		}
+		//sparse karena hanya tempat di trash yang memiliki deleted_at
+		index = mongo.IndexModel{Keys: bson.D{{Key: "deleted_at", Value: -1}}, Options: options.Index().SetSparse(true)}
+		if _, err := atdb.CreateIndex(Mongoconn, "tempat", index); err != nil {
+			log.Println("EnsureIndexes tempat deleted_at:", err)
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "kode", Value: 1}}, Options: options.Index().SetUnique(true)}
+		if _, err := atdb.CreateIndex(Mongoconn, "fasilitas", index); err != nil {
+			log.Println("EnsureIndexes fasilitas kode:", err)
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "location", Value: "2dsphere"}}}
+		if _, err := atdb.CreateIndex(Mongoconn, "penanda", index); err != nil {
+			log.Println("EnsureIndexes penanda 2dsphere:", err)
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "tempat_id", Value: 1}}}
+		if _, err := atdb.CreateIndex(Mongoconn, "penanda", index); err != nil {
+			log.Println("EnsureIndexes penanda tempat_id:", err)
+		}
+		//migrasi marker lama di-upsert per posisi, unique supaya migrasi yang berjalan bersamaan tidak menggandakan marker
+		index = mongo.IndexModel{Keys: bson.D{{Key: "legacy_idx", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)}
+		if _, err := atdb.CreateIndex(Mongoconn, "penanda", index); err != nil {
+			log.Println("EnsureIndexes penanda legacy_idx:", err)
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "tempat_id", Value: 1}, {Key: "waktu", Value: -1}}}
+		if _, err := atdb.CreateIndex(Mongoconn, "tempat_revisi", index); err != nil {
+			log.Println("EnsureIndexes tempat_revisi tempat_id:", err)
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "kode", Value: 1}}, Options: options.Index().SetUnique(true)}
+		if _, err := atdb.CreateIndex(Mongoconn, "tempat_usulan", index); err != nil {
+			log.Println("EnsureIndexes tempat_usulan kode:", err)
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "diajukan_at", Value: 1}}}
+		if _, err := atdb.CreateIndex(Mongoconn, "tempat_usulan", index); err != nil {
+			log.Println("EnsureIndexes tempat_usulan status:", err)
+		}
+		//satu reviewer hanya boleh satu ulasan per tempat
+		index = mongo.IndexModel{Keys: bson.D{{Key: "tempat_id", Value: 1}, {Key: "reviewer", Value: 1}}, Options: options.Index().SetUnique(true)}
+		if _, err := atdb.CreateIndex(Mongoconn, "ulasan", index); err != nil {
+			log.Println("EnsureIndexes ulasan tempat_id/reviewer:", err)
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "tempat_id", Value: 1}}}
+		if _, err := atdb.CreateIndex(Mongoconn, "laporan", index); err != nil {
+			log.Println("EnsureIndexes laporan status/tempat_id:", err)
+		}
+		//satu pelapor hanya boleh satu laporan open per tempat, laporan yang sudah ditriase tidak dihitung
+		index = mongo.IndexModel{
+			Keys:    bson.D{{Key: "tempat_id", Value: 1}, {Key: "pelapor", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": "open"}),
+		}
+		if _, err := atdb.CreateIndex(Mongoconn, "laporan", index); err != nil {
+			log.Println("EnsureIndexes laporan tempat_id/pelapor open:", err)
+		}
+		for _, field := range []string{"wilayah.kelurahan", "wilayah.kecamatan", "wilayah.kota"} {
+			index = mongo.IndexModel{Keys: bson.D{{Key: field, Value: 1}}}
+			if _, err := atdb.CreateIndex(Mongoconn, "tempat", index); err != nil {
+				log.Println("EnsureIndexes tempat "+field+":", err)
+			}
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "batas", Value: "2dsphere"}}}
+		if _, err := atdb.CreateIndex(Mongoconn, "lokasi", index); err != nil {
+			log.Println("EnsureIndexes lokasi 2dsphere:", err)
+		}
+		index = mongo.IndexModel{Keys: bson.D{{Key: "kategori", Value: 1}, {Key: "nama", Value: 1}}}
+		if _, err := atdb.CreateIndex(Mongoconn, "lokasi", index); err != nil {
+			log.Println("EnsureIndexes lokasi kategori:", err)
+		}
+		seedFasilitas()
+	})
+}
+
+func seedFasilitas() {
+	count, err := Mongoconn.Collection("fasilitas").EstimatedDocumentCount(context.Background())
+	if err != nil || count > 0 {
+		return
+	}
+	var docs []interface{}
+	for _, f := range DefaultFasilitas {
+		docs = append(docs, f)
+	}
+	if _, err := atdb.InsertManyDocs(Mongoconn, "fasilitas", docs); err != nil {
+		log.Println("EnsureIndexes seed fasilitas:", err)
+	}
+}
+
+// TempatLocationPipeline menyusun ulang field location dari lon/lat yang tersimpan
+var TempatLocationPipeline = mongo.Pipeline{
+	{{Key: "$set", Value: bson.M{
+		"location": bson.M{
+			"type":        "Point",
+			"coordinates": bson.A{"$lon", "$lat"},
+		},
+	}}},
+}
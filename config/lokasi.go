@@ -0,0 +1,64 @@
+package config
+
+import "time"
+
+// radius dalam meter
+var NearbyDefaultRadius float64 = 5000
+
+var NearbyMaxRadius float64 = 50000
+
+var NearbyDefaultLimit int64 = 20
+
+var NearbyMaxLimit int64 = 100
+
+// batas jumlah tempat per respon untuk query viewport peta
+var LokasiMaxResult int64 = 500
+
+// prefix URL gambar tempat yang diupload ke repo parkirgratis/filegambar
+var GambarBaseURL string = RepoGambarURL + GaleriFolder + "/"
+
+// ukuran maksimal file CSV import dalam byte
+var ImportMaxSize int64 = 5 << 20
+
+var ImportBatchSize int = 100
+
+var SearchDefaultLimit int64 = 20
+
+var SearchMaxLimit int64 = 100
+
+// jumlah karakter di kiri dan kanan kata yang cocok pada snippet highlight
+var SearchSnippetRadius int = 60
+
+// tempat yang akan tutup dalam durasi ini berstatus closing_soon
+var SegeraTutup time.Duration = 30 * time.Minute
+
+var JenisKendaraan = []string{"motor", "mobil", "sepeda"}
+
+// dokumen marker lama yang menyimpan semua koordinat dalam satu array
+var LegacyMarkerID string = "669510e39590720071a5691d"
+
+// di atas zoom ini GetCluster mengembalikan setiap tempat sebagai titik tunggal
+var ClusterMaxZoom int = 16
+
+// ukuran sel cluster dalam piksel layar
+var ClusterGridPx float64 = 60
+
+// batas default deteksi duplikat, jarak dalam meter dan kemiripan nama 0..1
+var DuplikatRadius float64 = 50
+
+var DuplikatKemiripan float64 = 0.6
+
+// ukuran maksimal file GeoJSON batas wilayah dalam byte
+var WilayahImportMaxSize int64 = 32 << 20
+
+// tempat di trash yang lebih lama dari ini boleh dihapus permanen lewat /admin/tempat/purge
+var TrashRetensiHari int = 30
+
+// kategori laporan publik, lokasi_salah diperbaiki lewat edit koordinat sehingga tidak punya padanan di StatusTempat
+var KategoriLaporan = []string{"tutup", "berbayar", "parkir_liar", "lokasi_salah"}
+
+// status tempat yang bisa diterapkan admin lewat POST /admin/laporan/terapkan
+var StatusTempat = []string{"tutup", "berbayar", "parkir_liar"}
+
+// tempat ditandai untuk dicek admin saat laporan open mencapai jumlah ini
+var LaporanAmbang int = 3
@@ -0,0 +1,29 @@
+package config
+
+// panjang maksimal teks pada model.Tempat, dihitung dalam karakter
+var NamaTempatMaxLen int = 100
+
+var LokasiMaxLen int = 300
+
+var FasilitasMaxLen int = 500
+
+var NamaGambarMaxLen int = 100
+
+var GambarExt = []string{".jpg", ".jpeg", ".png", ".webp", ".gif"}
+
+// AreaLayanan adalah poligon [lon, lat] wilayah yang dilayani, tempat dan marker di luar poligon ditolak.
+// Kosongkan untuk menerima koordinat mana saja. Default mencakup seluruh Indonesia.
+var AreaLayanan = [][]float64{
+	{94.0, 6.5},
+	{142.0, 6.5},
+	{142.0, -11.5},
+	{94.0, -11.5},
+	{94.0, 6.5},
+}
+
+var KomentarMaxLen int = 500
+
+var CaptionMaxLen int = 200
+
+// folder di repo filegambar tempat foto galeri disimpan, harus sama dengan akhir GambarBaseURL
+var GaleriFolder string = "img"
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/geo"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// clusterSel adalah hasil $group satu sel grid, Tempat hanya dipakai jika sel berisi satu tempat
+type clusterSel struct {
+	Jumlah int          `bson:"jumlah"`
+	Lon    float64      `bson:"lon"`
+	Lat    float64      `bson:"lat"`
+	MinLon float64      `bson:"min_lon"`
+	MinLat float64      `bson:"min_lat"`
+	MaxLon float64      `bson:"max_lon"`
+	MaxLat float64      `bson:"max_lat"`
+	Tempat model.Tempat `bson:"tempat"`
+}
+
+// GetCluster mengelompokkan semua tempat dalam ?bbox= per sel grid sesuai ?zoom=, filter daftar tempat (fasilitas, kendaraan, open_now) ikut berlaku.
+// Pengelompokan dikerjakan $group di Mongo atas seluruh viewport, paling banyak LokasiMaxResult cluster terbesar yang dikembalikan.
+func GetCluster(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	query := req.URL.Query()
+	if query.Get("bbox") == "" {
+		resp.Response = "Parameter bbox wajib diisi"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	zoom, err := strconv.Atoi(query.Get("zoom"))
+	if err != nil || zoom < 0 || zoom > 22 {
+		resp.Response = "Parameter zoom wajib diisi dengan angka 0 sampai 22"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	conds, err := tempatFilters(query)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	buka, err := parseWaktuBuka(query)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	if buka.saring {
+		//status buka dihitung di Go, jadi hanya tempat berjadwal yang dibaca lalu yang sedang tutup dikeluarkan dari $match
+		tutup, err := tempatTutup(conds, buka)
+		if err != nil {
+			resp.Response = err.Error()
+			helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+			return
+		}
+		if len(tutup) > 0 {
+			conds = append(conds, bson.M{"_id": bson.M{"$nin": tutup}})
+		}
+	}
+
+	//di atas ClusterMaxZoom setiap tempat menjadi selnya sendiri
+	var key interface{} = geo.GridKey(zoom, config.ClusterGridPx)
+	if zoom > config.ClusterMaxZoom {
+		key = "$_id"
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: andFilter(conds...)}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: key},
+			{Key: "jumlah", Value: bson.M{"$sum": 1}},
+			{Key: "lon", Value: bson.M{"$avg": "$lon"}},
+			{Key: "lat", Value: bson.M{"$avg": "$lat"}},
+			{Key: "min_lon", Value: bson.M{"$min": "$lon"}},
+			{Key: "min_lat", Value: bson.M{"$min": "$lat"}},
+			{Key: "max_lon", Value: bson.M{"$max": "$lon"}},
+			{Key: "max_lat", Value: bson.M{"$max": "$lat"}},
+			//cukup field ringkas untuk titik tunggal, detail lengkap dibuka lewat GET /data/tempat/:id
+			{Key: "tempat", Value: bson.M{"$first": bson.M{
+				"_id": "$_id", "nama_tempat": "$nama_tempat", "lon": "$lon", "lat": "$lat", "jam_buka": "$jam_buka",
+			}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "jumlah", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: config.LokasiMaxResult + 1}},
+	}
+	sel, err := atdb.GetAggregateDoc[[]clusterSel](config.Mongoconn, "tempat", pipeline)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+
+	list := model.ClusterList{Zoom: zoom}
+	if int64(len(sel)) > config.LokasiMaxResult {
+		sel = sel[:config.LokasiMaxResult]
+		list.Truncated = true
+	}
+	list.Clusters = clusterDariSel(sel, buka)
+	helper.WriteJSON(respw, http.StatusOK, list)
+}
+
+// tempatTutup mengembalikan _id tempat berjadwal dalam conds yang sedang tutup pada waktu buka
+func tempatTutup(conds []bson.M, buka waktuBuka) (bson.A, error) {
+	filter := andFilter(append(conds, bson.M{"jam_buka": bson.M{"$exists": true}})...)
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "jam_buka": 1})
+	tempat, err := atdb.GetAllDoc[[]model.Tempat](config.Mongoconn, "tempat", filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	tutup := bson.A{}
+	for i := range tempat {
+		if !buka.terapkan(&tempat[i]) {
+			tutup = append(tutup, tempat[i].ID)
+		}
+	}
+	return tutup, nil
+}
+
+// clusterDariSel mengubah hasil $group menjadi cluster, sel berisi satu tempat menjadi titik biasa lengkap dengan status buka
+func clusterDariSel(sel []clusterSel, buka waktuBuka) []model.Cluster {
+	clusters := make([]model.Cluster, 0, len(sel))
+	for _, s := range sel {
+		if s.Jumlah == 1 {
+			t := s.Tempat
+			buka.terapkan(&t)
+			clusters = append(clusters, model.Cluster{Lon: t.Lon, Lat: t.Lat, Jumlah: 1, Tempat: &t})
+			continue
+		}
+		clusters = append(clusters, model.Cluster{
+			Lon:    s.Lon,
+			Lat:    s.Lat,
+			Jumlah: s.Jumlah,
+			BBox:   []float64{s.MinLon, s.MinLat, s.MaxLon, s.MaxLat},
+		})
+	}
+	return clusters
+}
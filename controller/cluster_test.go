@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gocroot/helper/jadwal"
+	"github.com/gocroot/model"
+)
+
+func TestClusterDariSel(t *testing.T) {
+	sel := []clusterSel{
+		{Jumlah: 3, Lon: 106.9, Lat: -6.4, MinLon: 106.8, MinLat: -6.9, MaxLon: 107.6, MaxLat: -6.1, Tempat: model.Tempat{Nama_Tempat: "Monas"}},
+		{Jumlah: 1, Lon: 107.6, Lat: -6.9, Tempat: model.Tempat{Nama_Tempat: "Gedung Sate", Lon: 107.6186, Lat: -6.9025}},
+	}
+	clusters := clusterDariSel(sel, waktuBuka{at: time.Now()})
+	if len(clusters) != 2 {
+		t.Fatalf("clusterDariSel = %d cluster, want 2", len(clusters))
+	}
+
+	c := clusters[0]
+	if c.Jumlah != 3 || c.Tempat != nil || c.Lon != 106.9 || c.Lat != -6.4 {
+		t.Errorf("cluster = %+v, want centroid rata-rata tanpa tempat", c)
+	}
+	if want := []float64{106.8, -6.9, 107.6, -6.1}; !slices.Equal(c.BBox, want) {
+		t.Errorf("bbox = %v, want %v", c.BBox, want)
+	}
+
+	//titik tunggal memakai koordinat tempat, bukan rata-rata, dan membawa status buka
+	c = clusters[1]
+	if c.Jumlah != 1 || c.Tempat == nil || c.BBox != nil {
+		t.Fatalf("titik tunggal = %+v", c)
+	}
+	if c.Lon != 107.6186 || c.Lat != -6.9025 || c.Tempat.Nama_Tempat != "Gedung Sate" {
+		t.Errorf("titik tunggal = %+v, want koordinat Gedung Sate", c)
+	}
+	if c.Tempat.StatusBuka != jadwal.StatusTidakDiketahui {
+		t.Errorf("status buka = %q, want %q", c.Tempat.StatusBuka, jadwal.StatusTidakDiketahui)
+	}
+
+	if clusters := clusterDariSel(nil, waktuBuka{}); clusters == nil || len(clusters) != 0 {
+		t.Errorf("sel kosong = %#v, want slice kosong", clusters)
+	}
+}
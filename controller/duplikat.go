@@ -0,0 +1,298 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/geo"
+	"github.com/gocroot/helper/teks"
+	"github.com/gocroot/middleware"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetDuplikat menampilkan pasangan tempat dalam ?radius= meter yang kemiripan namanya minimal ?kemiripan=
+func GetDuplikat(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	query := req.URL.Query()
+	radius := config.DuplikatRadius
+	if query.Get("radius") != "" {
+		r, err := strconv.ParseFloat(query.Get("radius"), 64)
+		if err != nil || r <= 0 || r > 1000 {
+			resp.Response = "Parameter radius harus angka 1 sampai 1000 meter"
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		radius = r
+	}
+	kemiripan := config.DuplikatKemiripan
+	if query.Get("kemiripan") != "" {
+		k, err := strconv.ParseFloat(query.Get("kemiripan"), 64)
+		if err != nil || k < 0 || k > 1 {
+			resp.Response = "Parameter kemiripan harus angka 0 sampai 1"
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		kemiripan = k
+	}
+
+	tempat, err := atdb.GetAllDoc[[]model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"location": bson.M{"$exists": true}}))
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, cariDuplikat(tempat, radius, kemiripan))
+}
+
+// cariDuplikat mengurutkan tempat berdasarkan lintang lalu hanya membandingkan tempat yang selisih lintangnya masih dalam radius
+func cariDuplikat(tempat []model.Tempat, radius, minKemiripan float64) []model.KandidatDuplikat {
+	sort.Slice(tempat, func(i, j int) bool { return tempat[i].Lat < tempat[j].Lat })
+	batasLat := geo.MeterKeDerajatLat(radius)
+	kandidat := []model.KandidatDuplikat{}
+	for i := range tempat {
+		for j := i + 1; j < len(tempat) && tempat[j].Lat-tempat[i].Lat <= batasLat; j++ {
+			jarak := geo.Haversine(tempat[i].Lon, tempat[i].Lat, tempat[j].Lon, tempat[j].Lat)
+			if jarak > radius {
+				continue
+			}
+			kemiripan := teks.Kemiripan(tempat[i].Nama_Tempat, tempat[j].Nama_Tempat)
+			if kemiripan < minKemiripan {
+				continue
+			}
+			kandidat = append(kandidat, model.KandidatDuplikat{A: tempat[i], B: tempat[j], Jarak: jarak, Kemiripan: kemiripan})
+		}
+	}
+	sort.Slice(kandidat, func(i, j int) bool { return kandidat[i].Kemiripan > kandidat[j].Kemiripan })
+	return kandidat
+}
+
+// PostMergeDuplikat menggabungkan duplikat_id ke utama_id, marker milik duplikat dipindah ke utama lalu duplikat masuk trash
+func PostMergeDuplikat(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	var body struct {
+		UtamaID    string `json:"utama_id"`
+		DuplikatID string `json:"duplikat_id"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	utamaID, errUtama := primitive.ObjectIDFromHex(body.UtamaID)
+	duplikatID, errDuplikat := primitive.ObjectIDFromHex(body.DuplikatID)
+	if errUtama != nil || errDuplikat != nil || utamaID == duplikatID {
+		resp.Response = "utama_id dan duplikat_id harus ID tempat yang berbeda"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+
+	utama, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"_id": utamaID}))
+	if err != nil {
+		writeTempatError(respw, err)
+		return
+	}
+	duplikat, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"_id": duplikatID}))
+	if err != nil {
+		writeTempatError(respw, err)
+		return
+	}
+	mergeTempat(respw, req, utama, duplikat)
+}
+
+// writeTempatError membedakan tempat yang tidak ada dengan kegagalan database
+func writeTempatError(respw http.ResponseWriter, err error) {
+	var resp itmodel.Response
+	if err == mongo.ErrNoDocuments {
+		resp.Response = "Tempat tidak ditemukan"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+		return
+	}
+	resp.Response = err.Error()
+	helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+}
+
+func mergeTempat(respw http.ResponseWriter, req *http.Request, utama, duplikat model.Tempat) {
+	var resp itmodel.Response
+	hasil := gabungTempat(utama, duplikat)
+	//replace tidak bisa memakai $inc, versi dinaikkan di dokumen dan filter versi menjaga dari update lain sejak utama dibaca
+	hasil.Versi = utama.Versi + 1
+	result, err := atdb.ReplaceOneDoc(config.Mongoconn, "tempat", versiFilter(tempatAktif(bson.M{"_id": utama.ID}), utama.Versi), hasil)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if result.MatchedCount == 0 {
+		writeTempatKonflik(respw, utama.ID)
+		return
+	}
+	pindah, err := atdb.UpdateManyDoc(config.Mongoconn, "penanda", bson.M{"tempat_id": duplikat.ID}, bson.M{"$set": bson.M{"tempat_id": utama.ID, "updated_at": time.Now().UTC()}})
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if err := pindahUlasan(duplikat.ID, utama.ID); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if err := pindahLaporan(duplikat.ID, utama.ID); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if err := perbaruiRingkasanLaporan(utama.ID); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+
+	dihapus := duplikat
+	now := time.Now().UTC()
+	dihapus.DeletedAt = &now
+	dihapus.DeletedBy = middleware.GetAdminID(req)
+	dihapus.Versi++
+	hapus := bson.M{"$set": bson.M{"deleted_at": dihapus.DeletedAt, "deleted_by": dihapus.DeletedBy}, "$inc": bson.M{"versi": 1}}
+	trash, err := atdb.UpdateDoc(config.Mongoconn, "tempat", versiFilter(tempatAktif(bson.M{"_id": duplikat.ID}), duplikat.Versi), hapus)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	//gabungTempat aman diulang, admin cukup mengirim merge lagi setelah melihat versi terbaru duplikat
+	if trash.MatchedCount == 0 {
+		writeTempatKonflik(respw, duplikat.ID)
+		return
+	}
+	revisi := []interface{}{
+		revisiTempat(req, AksiMerge, &utama, &hasil),
+		revisiTempat(req, AksiMerge, &duplikat, &dihapus),
+	}
+	if _, err := atdb.InsertManyDocs(config.Mongoconn, "tempat_revisi", revisi); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+
+	catatan := model.MergeTempat{
+		UtamaID:      utama.ID,
+		DuplikatID:   duplikat.ID,
+		Sebelum:      utama,
+		Duplikat:     duplikat,
+		Hasil:        hasil,
+		MarkerPindah: pindah.ModifiedCount,
+		AdminID:      middleware.GetAdminID(req),
+		Waktu:        time.Now().UTC(),
+	}
+	id, err := atdb.InsertOneDoc(config.Mongoconn, "tempat_merge", catatan)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	catatan.ID = id.(primitive.ObjectID)
+	helper.WriteJSON(respw, http.StatusOK, catatan)
+}
+
+// pindahUlasan memindah ulasan duplikat ke utama, kecuali reviewer yang sudah mengulas utama supaya tetap satu ulasan per tempat
+func pindahUlasan(dariID, keID primitive.ObjectID) error {
+	sudah, err := atdb.GetAllDoc[[]model.Ulasan](config.Mongoconn, "ulasan", bson.M{"tempat_id": keID})
+	if err != nil {
+		return err
+	}
+	reviewer := bson.A{}
+	for _, u := range sudah {
+		reviewer = append(reviewer, u.Reviewer)
+	}
+	filter := bson.M{"tempat_id": dariID, "reviewer": bson.M{"$nin": reviewer}}
+	if _, err := atdb.UpdateManyDoc(config.Mongoconn, "ulasan", filter, bson.M{"$set": bson.M{"tempat_id": keID}}); err != nil {
+		return err
+	}
+	return perbaruiRating(keID)
+}
+
+// pindahLaporan memindah laporan ke tempat tujuan, laporan open dari pelapor yang sudah punya laporan open di tujuan tetap di asal
+func pindahLaporan(dariID, keID primitive.ObjectID) error {
+	sudah, err := atdb.GetAllDoc[[]model.Laporan](config.Mongoconn, "laporan", bson.M{"tempat_id": keID, "status": LaporanOpen})
+	if err != nil {
+		return err
+	}
+	pelapor := bson.A{}
+	for _, l := range sudah {
+		pelapor = append(pelapor, l.Pelapor)
+	}
+	filter := bson.M{"tempat_id": dariID, "$nor": bson.A{bson.M{"status": LaporanOpen, "pelapor": bson.M{"$in": pelapor}}}}
+	_, err = atdb.UpdateManyDoc(config.Mongoconn, "laporan", filter, bson.M{"$set": bson.M{"tempat_id": keID}})
+	return err
+}
+
+// gabungTempat mempertahankan nama dan koordinat utama, field kosong diisi dari duplikat,
+// teks keterangan diambil yang lebih lengkap dan daftar fasilitas digabung
+func gabungTempat(utama, duplikat model.Tempat) model.Tempat {
+	hasil := utama
+	hasil.Lokasi = lebihPanjang(utama.Lokasi, duplikat.Lokasi)
+	hasil.Fasilitas = lebihPanjang(utama.Fasilitas, duplikat.Fasilitas)
+	hasil.Galeri = gabungGaleri(utama.Galeri, duplikat.Galeri)
+	if cover := coverGaleri(hasil.Galeri); cover != "" {
+		hasil.Gambar = cover
+	} else if hasil.Gambar == "" {
+		hasil.Gambar = duplikat.Gambar
+	}
+	if hasil.JamBuka == nil {
+		hasil.JamBuka = duplikat.JamBuka
+	}
+	hasil.FasilitasTags = gabungUnik(utama.FasilitasTags, duplikat.FasilitasTags)
+	hasil.FasilitasReview = gabungUnik(utama.FasilitasReview, duplikat.FasilitasReview)
+	hasil.Versi = utama.Versi + 1
+	if len(duplikat.Kendaraan) > 0 {
+		hasil.Kendaraan = map[string]model.KendaraanInfo{}
+		for jenis, info := range duplikat.Kendaraan {
+			hasil.Kendaraan[jenis] = info
+		}
+		for jenis, info := range utama.Kendaraan {
+			hasil.Kendaraan[jenis] = info
+		}
+	}
+	return hasil
+}
+
+// gabungGaleri menambahkan foto duplikat yang URL-nya belum ada di belakang galeri utama, cover utama dipertahankan
+func gabungGaleri(utama, duplikat []model.Foto) []model.Foto {
+	hasil := slices.Clone(utama)
+	for _, f := range duplikat {
+		if !slices.ContainsFunc(hasil, func(g model.Foto) bool { return g.URL == f.URL }) {
+			f.Cover = false
+			hasil = append(hasil, f)
+		}
+	}
+	return rapikanCover(hasil)
+}
+
+func lebihPanjang(a, b string) string {
+	if len(b) > len(a) {
+		return b
+	}
+	return a
+}
+
+func gabungUnik(a, b []string) []string {
+	hasil := slices.Clone(a)
+	for _, v := range b {
+		if !slices.Contains(hasil, v) {
+			hasil = append(hasil, v)
+		}
+	}
+	return hasil
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errVersi = errors.New("header If-Match tidak berisi versi yang valid")
+
+// etagTempat menyusun ETag dari versi tempat, contoh "3"
+func etagTempat(t model.Tempat) string {
+	return strconv.Quote(strconv.FormatInt(t.Versi, 10))
+}
+
+// ifMatchVersi membaca versi dari header If-Match, ok false jika header tidak dikirim.
+// Weak ETag (W/"3") diterima karena versi tidak bergantung pada representasi.
+func ifMatchVersi(req *http.Request) (versi int64, ok bool, err error) {
+	header := strings.TrimSpace(req.Header.Get("If-Match"))
+	if header == "" {
+		return 0, false, nil
+	}
+	header = strings.TrimPrefix(header, "W/")
+	if unquoted, errQuote := strconv.Unquote(header); errQuote == nil {
+		header = unquoted
+	}
+	versi, err = strconv.ParseInt(header, 10, 64)
+	if err != nil || versi < 0 {
+		return 0, true, errVersi
+	}
+	return versi, true, nil
+}
+
+// syaratVersi membaca If-Match dan menulis 428 jika header tidak ada atau 400 jika formatnya salah
+func syaratVersi(respw http.ResponseWriter, req *http.Request) (int64, bool) {
+	var resp itmodel.Response
+	versi, ok, err := ifMatchVersi(req)
+	if !ok {
+		resp.Response = "Header If-Match wajib dikirim, ambil versi terbaru dari ETag"
+		helper.WriteJSON(respw, http.StatusPreconditionRequired, resp)
+		return 0, false
+	}
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return 0, false
+	}
+	return versi, true
+}
+
+// versiFilter menambahkan syarat versi ke filter, versi 0 juga cocok dengan dokumen lama yang belum punya field versi
+func versiFilter(filter bson.M, versi int64) bson.M {
+	if versi == 0 {
+		filter["versi"] = bson.M{"$in": bson.A{0, nil}}
+	} else {
+		filter["versi"] = versi
+	}
+	return filter
+}
+
+// writeTempatKonflik dipakai saat update bersyarat versi tidak menemukan dokumen, tempat bisa sudah berubah atau sudah dihapus
+func writeTempatKonflik(respw http.ResponseWriter, id primitive.ObjectID) {
+	current, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"_id": id}))
+	if err != nil {
+		writeTempatError(respw, err)
+		return
+	}
+	writeKonflikVersi(respw, current)
+}
+
+// writeKonflikVersi mengirim 412 beserta dokumen terbaru supaya admin bisa melihat perubahan orang lain
+func writeKonflikVersi(respw http.ResponseWriter, current model.Tempat) {
+	respw.Header().Set("ETag", etagTempat(current))
+	helper.WriteJSON(respw, http.StatusPreconditionFailed, current)
+}
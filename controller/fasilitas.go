@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var kodeFasilitas = regexp.MustCompile(`^[a-z0-9_]{2,32}$`)
+
+// pemisah antar fasilitas pada teks bebas, contoh "CCTV, toilet dan atap"
+var pemisahFasilitas = regexp.MustCompile(`(?i)\s*(?:[,;/\n&+]|\bdan\b)\s*`)
+
+func GetFasilitas(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	opts := options.Find().SetSort(bson.M{"kode": 1})
+	fasilitas, err := atdb.GetAllDoc[[]model.FasilitasTag](config.Mongoconn, "fasilitas", bson.M{}, opts)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if fasilitas == nil {
+		fasilitas = []model.FasilitasTag{}
+	}
+	helper.WriteJSON(respw, http.StatusOK, fasilitas)
+}
+
+// PostFasilitas menambah atau memperbarui kosakata fasilitas berdasarkan kode
+func PostFasilitas(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	var fasilitas model.FasilitasTag
+	if err := json.NewDecoder(req.Body).Decode(&fasilitas); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	fasilitas.Kode = strings.ToLower(strings.TrimSpace(fasilitas.Kode))
+	if !kodeFasilitas.MatchString(fasilitas.Kode) {
+		resp.Response = "kode harus 2-32 karakter huruf kecil, angka atau garis bawah"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	if strings.TrimSpace(fasilitas.Nama) == "" {
+		resp.Response = "nama wajib diisi"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	for i, alias := range fasilitas.Alias {
+		fasilitas.Alias[i] = strings.ToLower(strings.TrimSpace(alias))
+	}
+
+	update := bson.M{"$set": bson.M{"nama": fasilitas.Nama, "alias": fasilitas.Alias}}
+	_, err := config.Mongoconn.Collection("fasilitas").UpdateOne(req.Context(), bson.M{"kode": fasilitas.Kode}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	fasilitas, err = atdb.GetOneDoc[model.FasilitasTag](config.Mongoconn, "fasilitas", bson.M{"kode": fasilitas.Kode})
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, fasilitas)
+}
+
+// DeleteFasilitas menghapus kode dari kosakata sekaligus dari semua tempat yang memakainya
+func DeleteFasilitas(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	var requestBody struct {
+		Kode string `json:"kode"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&requestBody); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+
+	deletedCount, err := atdb.DeleteOneDoc(config.Mongoconn, "fasilitas", bson.M{"kode": requestBody.Kode})
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if deletedCount == 0 {
+		resp.Response = "Fasilitas tidak ditemukan"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+		return
+	}
+	terdampak, err := atdb.GetAllDoc[[]model.Tempat](config.Mongoconn, "tempat", bson.M{"fasilitas_tags": requestBody.Kode})
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	ids := bson.A{}
+	var revisi []interface{}
+	for i := range terdampak {
+		ids = append(ids, terdampak[i].ID)
+		sesudah := terdampak[i]
+		sesudah.FasilitasTags = slices.DeleteFunc(slices.Clone(sesudah.FasilitasTags), func(kode string) bool { return kode == requestBody.Kode })
+		sesudah.Versi++
+		revisi = append(revisi, revisiTempat(req, AksiUpdate, &terdampak[i], &sesudah))
+	}
+	//versi ikut naik supaya ETag yang dipegang klien tidak lagi cocok dengan data yang sudah berubah
+	update := bson.M{"$pull": bson.M{"fasilitas_tags": requestBody.Kode}, "$inc": bson.M{"versi": 1}}
+	result, err := atdb.UpdateManyDoc(config.Mongoconn, "tempat", bson.M{"_id": bson.M{"$in": ids}, "fasilitas_tags": requestBody.Kode}, update)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if len(revisi) > 0 {
+		if _, err := atdb.InsertManyDocs(config.Mongoconn, "tempat_revisi", revisi); err != nil {
+			resp.Response = err.Error()
+			helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+			return
+		}
+	}
+	resp.Response = "Fasilitas dihapus"
+	resp.Info = helper.Jsonstr(result.ModifiedCount) + " tempat diperbarui"
+	helper.WriteJSON(respw, http.StatusOK, resp)
+}
+
+// PostMigrasiFasilitas mengisi fasilitas_tags dari teks fasilitas lama, potongan yang tidak dikenali disimpan di fasilitas_review.
+// Tempat yang sudah memiliki fasilitas_tags dilewati, ?dry_run=true hanya menampilkan hasil parsing.
+func PostMigrasiFasilitas(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	vocab, err := atdb.GetAllDoc[[]model.FasilitasTag](config.Mongoconn, "fasilitas", bson.M{})
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	filter := bson.M{
+		"fasilitas":      bson.M{"$exists": true, "$ne": ""},
+		"fasilitas_tags": bson.M{"$exists": false},
+	}
+	tempat, err := atdb.GetAllDoc[[]model.Tempat](config.Mongoconn, "tempat", filter)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+
+	report := model.MigrasiFasilitas{
+		DryRun: req.URL.Query().Get("dry_run") == "true",
+		Total:  len(tempat),
+		Detail: []model.MigrasiFasilitasItem{},
+	}
+	for _, t := range tempat {
+		tags, review := parseFasilitas(t.Fasilitas, vocab)
+		item := model.MigrasiFasilitasItem{ID: t.ID.Hex(), Fasilitas: t.Fasilitas, Tags: tags, Review: review}
+		report.Detail = append(report.Detail, item)
+		if len(review) > 0 {
+			report.PerluCek++
+		}
+		if report.DryRun {
+			continue
+		}
+		//tags kosong tetap disimpan sebagai array supaya tempat ini tidak diproses ulang
+		set := bson.M{"fasilitas_tags": append([]string{}, tags...)}
+		if len(review) > 0 {
+			set["fasilitas_review"] = review
+		}
+		if _, err := atdb.UpdateDoc(config.Mongoconn, "tempat", bson.M{"_id": t.ID}, bson.M{"$set": set, "$inc": bson.M{"versi": 1}}); err != nil {
+			resp.Response = err.Error()
+			helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+			return
+		}
+		sesudah := t
+		sesudah.FasilitasTags, sesudah.FasilitasReview = tags, review
+		sesudah.Versi++
+		if err := catatRevisi(req, AksiUpdate, &t, &sesudah); err != nil {
+			resp.Response = err.Error()
+			helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+			return
+		}
+		report.Diproses++
+	}
+	helper.WriteJSON(respw, http.StatusOK, report)
+}
+
+// parseFasilitas memecah teks bebas lalu mencocokkan tiap potongan dengan kode, nama atau alias di kosakata
+func parseFasilitas(text string, vocab []model.FasilitasTag) (tags []string, review []string) {
+	found := map[string]bool{}
+	for _, part := range pemisahFasilitas.Split(text, -1) {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		matched := false
+		for _, f := range vocab {
+			for _, term := range append([]string{f.Kode, strings.ToLower(f.Nama)}, f.Alias...) {
+				if term != "" && containsWord(part, term) {
+					matched = true
+					if !found[f.Kode] {
+						found[f.Kode] = true
+						tags = append(tags, f.Kode)
+					}
+					break
+				}
+			}
+		}
+		if !matched {
+			review = append(review, part)
+		}
+	}
+	return
+}
+
+func containsWord(text, word string) bool {
+	return regexp.MustCompile(`\b` + regexp.QuoteMeta(word) + `\b`).MatchString(text)
+}
+
+// normalizeFasilitasTags menyeragamkan tag lalu mengembalikan tag yang tidak ada di kosakata fasilitas
+func normalizeFasilitasTags(tags []string) (normalized []string, unknown []string, err error) {
+	if len(tags) == 0 {
+		return tags, nil, nil
+	}
+	normalized = make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	vocab, err := atdb.GetAllDoc[[]model.FasilitasTag](config.Mongoconn, "fasilitas", bson.M{"kode": bson.M{"$in": normalized}})
+	if err != nil {
+		return nil, nil, err
+	}
+	known := map[string]bool{}
+	for _, f := range vocab {
+		known[f.Kode] = true
+	}
+	for _, tag := range normalized {
+		if !known[tag] {
+			unknown = append(unknown, tag)
+		}
+	}
+	return normalized, unknown, nil
+}
@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/gambar"
+	"github.com/gocroot/helper/validasi"
+	"github.com/gocroot/middleware"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostFotoGaleri mengunggah field img ke repo filegambar seperti PostUploadGithub (varian web dan thumb) lalu menambahkannya ke galeri tempat :id.
+// Field form caption opsional, cover=true menjadikan foto ini cover.
+func PostFotoGaleri(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	tempatID, err := primitive.ObjectIDFromHex(helper.GetParam(req))
+	if err != nil {
+		resp.Response = "ID tempat tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	header, ok := formGambar(respw, req)
+	if !ok {
+		return
+	}
+	caption := strings.TrimSpace(req.FormValue("caption"))
+	var errs validasi.Errors
+	validasi.Teks(&errs, "caption", caption, false, config.CaptionMaxLen)
+	if len(errs) > 0 {
+		writeValidasi(respw, errs)
+		return
+	}
+	sebelum, ok := tempatGaleri(respw, req, tempatID)
+	if !ok {
+		return
+	}
+
+	paths, ok := unggahGambar(respw, req, header, config.GaleriFolder)
+	if !ok {
+		return
+	}
+
+	foto := fotoGambar(config.RepoGambarURL+paths[gambar.VarianWeb], middleware.GetAdminID(req), time.Now().UTC())
+	foto.Thumb = config.RepoGambarURL + paths[gambar.VarianThumb]
+	foto.Caption = caption
+	foto.Cover = req.FormValue("cover") == "true"
+	galeri := slices.Clone(sebelum.Galeri)
+	if foto.Cover {
+		for i := range galeri {
+			galeri[i].Cover = false
+		}
+	}
+	simpanGaleri(respw, req, sebelum, append(galeri, foto))
+}
+
+// PutUrutanGaleri mengatur ulang urutan foto, urutan harus berisi semua _id foto tepat satu kali
+func PutUrutanGaleri(respw http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Urutan []string `json:"urutan"`
+	}
+	tempatID, ok := bodyGaleri(respw, req, &body)
+	if !ok {
+		return
+	}
+	sebelum, ok := tempatGaleri(respw, req, tempatID)
+	if !ok {
+		return
+	}
+	if len(body.Urutan) != len(sebelum.Galeri) {
+		writeValidasi(respw, []model.FieldError{{Field: "urutan", Pesan: "harus berisi semua _id foto di galeri"}})
+		return
+	}
+	galeri := make([]model.Foto, 0, len(sebelum.Galeri))
+	for i, idHex := range body.Urutan {
+		j := indexFoto(sebelum.Galeri, idHex)
+		if j < 0 || slices.ContainsFunc(galeri, func(f model.Foto) bool { return f.ID == sebelum.Galeri[j].ID }) {
+			writeValidasi(respw, []model.FieldError{{Field: "urutan[" + strconv.Itoa(i) + "]", Pesan: "_id foto tidak dikenal atau ganda"}})
+			return
+		}
+		galeri = append(galeri, sebelum.Galeri[j])
+	}
+	simpanGaleri(respw, req, sebelum, galeri)
+}
+
+// PutCoverGaleri menjadikan foto_id sebagai cover, Tempat.Gambar ikut berubah
+func PutCoverGaleri(respw http.ResponseWriter, req *http.Request) {
+	var body struct {
+		FotoID string `json:"foto_id"`
+	}
+	tempatID, ok := bodyGaleri(respw, req, &body)
+	if !ok {
+		return
+	}
+	sebelum, ok := tempatGaleri(respw, req, tempatID)
+	if !ok {
+		return
+	}
+	j := indexFoto(sebelum.Galeri, body.FotoID)
+	if j < 0 {
+		writeFotoTidakAda(respw)
+		return
+	}
+	galeri := slices.Clone(sebelum.Galeri)
+	for i := range galeri {
+		galeri[i].Cover = i == j
+	}
+	simpanGaleri(respw, req, sebelum, galeri)
+}
+
+// DeleteFotoGaleri melepas foto dari galeri, file di repo filegambar tidak dihapus karena bisa masih dirujuk revisi lama
+func DeleteFotoGaleri(respw http.ResponseWriter, req *http.Request) {
+	var body struct {
+		FotoID string `json:"foto_id"`
+	}
+	tempatID, ok := bodyGaleri(respw, req, &body)
+	if !ok {
+		return
+	}
+	sebelum, ok := tempatGaleri(respw, req, tempatID)
+	if !ok {
+		return
+	}
+	j := indexFoto(sebelum.Galeri, body.FotoID)
+	if j < 0 {
+		writeFotoTidakAda(respw)
+		return
+	}
+	simpanGaleri(respw, req, sebelum, slices.Delete(slices.Clone(sebelum.Galeri), j, j+1))
+}
+
+// PostMigrasiGaleri memindahkan gambar tunggal lama menjadi galeri berisi satu foto cover, ?dry_run=true hanya menghitung
+func PostMigrasiGaleri(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	filter := bson.M{
+		"gambar": bson.M{"$exists": true, "$ne": ""},
+		"galeri": bson.M{"$exists": false},
+	}
+	tempat, err := atdb.GetAllDoc[[]model.Tempat](config.Mongoconn, "tempat", filter)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	report := model.MigrasiGaleri{
+		DryRun: req.URL.Query().Get("dry_run") == "true",
+		Total:  len(tempat),
+		IDs:    []string{},
+	}
+	for _, t := range tempat {
+		report.IDs = append(report.IDs, t.ID.Hex())
+		if report.DryRun {
+			continue
+		}
+		//waktu unggah asli tidak tercatat, dipakai waktu pembuatan tempat dari ObjectID
+		galeri := []model.Foto{fotoGambar(t.Gambar, "", t.ID.Timestamp().UTC())}
+		galeri[0].Cover = true
+		update := bson.M{"$set": bson.M{"galeri": galeri}, "$inc": bson.M{"versi": 1}}
+		result, err := atdb.UpdateDoc(config.Mongoconn, "tempat", bson.M{"_id": t.ID, "galeri": bson.M{"$exists": false}}, update)
+		if err != nil {
+			resp.Response = err.Error()
+			helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+			return
+		}
+		if result.ModifiedCount == 0 {
+			continue
+		}
+		sesudah := t
+		sesudah.Galeri = galeri
+		sesudah.Versi++
+		if err := catatRevisi(req, AksiUpdate, &t, &sesudah); err != nil {
+			resp.Response = err.Error()
+			helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+			return
+		}
+		report.Diproses++
+	}
+	helper.WriteJSON(respw, http.StatusOK, report)
+}
+
+// bodyGaleri membaca :id tempat dari path dan body JSON, menulis 400 jika salah satunya tidak valid
+func bodyGaleri(respw http.ResponseWriter, req *http.Request, body interface{}) (primitive.ObjectID, bool) {
+	var resp itmodel.Response
+	tempatID, err := primitive.ObjectIDFromHex(helper.GetParam(req))
+	if err != nil {
+		resp.Response = "ID tempat tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return tempatID, false
+	}
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return tempatID, false
+	}
+	return tempatID, true
+}
+
+// tempatGaleri mengambil tempat yang galerinya akan diubah, If-Match tidak wajib tetapi dicek jika dikirim
+func tempatGaleri(respw http.ResponseWriter, req *http.Request, tempatID primitive.ObjectID) (model.Tempat, bool) {
+	var resp itmodel.Response
+	versi, adaIfMatch, err := ifMatchVersi(req)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return model.Tempat{}, false
+	}
+	tempat, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"_id": tempatID}))
+	if err != nil {
+		writeTempatError(respw, err)
+		return tempat, false
+	}
+	if adaIfMatch && tempat.Versi != versi {
+		writeKonflikVersi(respw, tempat)
+		return tempat, false
+	}
+	return tempat, true
+}
+
+// simpanGaleri menyimpan galeri baru dengan tepat satu cover, Gambar disamakan dengan URL cover
+func simpanGaleri(respw http.ResponseWriter, req *http.Request, sebelum model.Tempat, galeri []model.Foto) {
+	var resp itmodel.Response
+	galeri = rapikanCover(galeri)
+	update := bson.M{"$inc": bson.M{"versi": 1}}
+	if len(galeri) == 0 {
+		update["$unset"] = bson.M{"galeri": "", "gambar": ""}
+	} else {
+		update["$set"] = bson.M{"galeri": galeri, "gambar": coverGaleri(galeri)}
+	}
+	result, err := atdb.UpdateDoc(config.Mongoconn, "tempat", versiFilter(tempatAktif(bson.M{"_id": sebelum.ID}), sebelum.Versi), update)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if result.MatchedCount == 0 {
+		writeTempatKonflik(respw, sebelum.ID)
+		return
+	}
+	sesudah, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", bson.M{"_id": sebelum.ID})
+	if err != nil {
+		writeTempatError(respw, err)
+		return
+	}
+	if err := catatRevisi(req, AksiUpdate, &sebelum, &sesudah); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	respw.Header().Set("ETag", etagTempat(sesudah))
+	helper.WriteJSON(respw, http.StatusOK, sesudah)
+}
+
+func writeFotoTidakAda(respw http.ResponseWriter) {
+	helper.WriteJSON(respw, http.StatusNotFound, itmodel.Response{Response: "Foto tidak ditemukan di galeri"})
+}
+
+func fotoGambar(url, uploader string, waktu time.Time) model.Foto {
+	return model.Foto{
+		ID:         primitive.NewObjectID(),
+		URL:        url,
+		Uploader:   uploader,
+		UploadedAt: waktu,
+	}
+}
+
+func indexFoto(galeri []model.Foto, idHex string) int {
+	id, err := primitive.ObjectIDFromHex(idHex)
+	if err != nil {
+		return -1
+	}
+	return slices.IndexFunc(galeri, func(f model.Foto) bool { return f.ID == id })
+}
+
+// rapikanCover menyisakan cover pertama, jika tidak ada cover foto pertama yang dijadikan cover
+func rapikanCover(galeri []model.Foto) []model.Foto {
+	ada := false
+	for i := range galeri {
+		if galeri[i].Cover && !ada {
+			ada = true
+		} else {
+			galeri[i].Cover = false
+		}
+	}
+	if !ada && len(galeri) > 0 {
+		galeri[0].Cover = true
+	}
+	return galeri
+}
+
+func coverGaleri(galeri []model.Foto) string {
+	for _, f := range galeri {
+		if f.Cover {
+			return f.URL
+		}
+	}
+	return ""
+}
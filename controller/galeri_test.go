@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocroot/model"
+)
+
+func galeriUji(urls ...string) []model.Foto {
+	galeri := make([]model.Foto, 0, len(urls))
+	for _, url := range urls {
+		galeri = append(galeri, fotoGambar(url, "", time.Time{}))
+	}
+	return galeri
+}
+
+func coverIndex(galeri []model.Foto) (idx []int) {
+	for i, f := range galeri {
+		if f.Cover {
+			idx = append(idx, i)
+		}
+	}
+	return
+}
+
+func TestRapikanCover(t *testing.T) {
+	if got := rapikanCover(nil); len(got) != 0 {
+		t.Errorf("galeri kosong = %v", got)
+	}
+
+	galeri := rapikanCover(galeriUji("a", "b", "c"))
+	if idx := coverIndex(galeri); len(idx) != 1 || idx[0] != 0 {
+		t.Errorf("tanpa cover: cover di %v, want [0]", idx)
+	}
+
+	galeri = galeriUji("a", "b", "c")
+	galeri[1].Cover, galeri[2].Cover = true, true
+	galeri = rapikanCover(galeri)
+	if idx := coverIndex(galeri); len(idx) != 1 || idx[0] != 1 {
+		t.Errorf("dua cover: cover di %v, want [1]", idx)
+	}
+	if coverGaleri(galeri) != "b" {
+		t.Errorf("coverGaleri = %q, want b", coverGaleri(galeri))
+	}
+	if coverGaleri(nil) != "" {
+		t.Error("coverGaleri galeri kosong harus string kosong")
+	}
+}
+
+func TestGabungGaleri(t *testing.T) {
+	utama := galeriUji("a", "b")
+	utama[1].Cover = true
+	duplikat := galeriUji("b", "c")
+	duplikat[1].Cover = true
+
+	hasil := gabungGaleri(utama, duplikat)
+	if len(hasil) != 3 || hasil[0].URL != "a" || hasil[1].URL != "b" || hasil[2].URL != "c" {
+		t.Fatalf("gabungGaleri = %+v, want a, b, c", hasil)
+	}
+	if idx := coverIndex(hasil); len(idx) != 1 || idx[0] != 1 {
+		t.Errorf("cover utama harus dipertahankan, cover di %v", idx)
+	}
+	if utama[0].Cover || len(utama) != 2 {
+		t.Error("galeri utama tidak boleh ikut berubah")
+	}
+
+	//utama tanpa galeri memakai foto duplikat, cover duplikat dilepas lalu foto pertama jadi cover
+	hasil = gabungGaleri(nil, duplikat)
+	if len(hasil) != 2 || !hasil[0].Cover || hasil[1].Cover {
+		t.Errorf("utama kosong = %+v", hasil)
+	}
+}
+
+func TestIndexFoto(t *testing.T) {
+	galeri := galeriUji("a", "b")
+	if i := indexFoto(galeri, galeri[1].ID.Hex()); i != 1 {
+		t.Errorf("indexFoto = %d, want 1", i)
+	}
+	for _, id := range []string{"", "bukan-hex", "000000000000000000000000"} {
+		if i := indexFoto(galeri, id); i != -1 {
+			t.Errorf("indexFoto(%q) = %d, want -1", id, i)
+		}
+	}
+}
@@ -1,11 +1,22 @@
 package controller
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/gocroot/config"
 	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/gambar"
+	"github.com/gocroot/helper/validasi"
 	"github.com/gocroot/model"
 	"go.mongodb.org/mongo-driver/bson"
 
@@ -19,48 +30,121 @@ func PostUploadGithub(w http.ResponseWriter, r *http.Request) {
 
 	fmt.Println("Starting file upload process")
 
+	folder := helper.GetParam(r)
+	if !validasi.Folder(folder) {
+		respn.Response = "folder hanya boleh huruf, angka, - dan _ tanpa / atau titik"
+		helper.WriteJSON(w, http.StatusBadRequest, respn)
+		return
+	}
+	header, ok := formGambar(w, r)
+	if !ok {
+		return
+	}
+
+	paths, ok := unggahGambar(w, r, header, folder)
+	if !ok {
+		return
+	}
+
+	hasil := model.HasilUpload{Varian: map[string]string{}}
+	for nama, path := range paths {
+		hasil.Varian[nama] = config.RepoGambarURL + path
+	}
+	//response dan info tetap berisi path dan nama file seperti sebelumnya, diisi varian web
+	hasil.Response = paths[gambar.VarianWeb]
+	hasil.Info = filepath.Base(hasil.Response)
+	helper.WriteJSON(w, http.StatusOK, hasil)
+	fmt.Println("File upload process completed successfully")
+}
+
+// formGambar membaca field img dengan batas ukuran GambarMaxSize, jika gagal respon error sudah ditulis
+func formGambar(w http.ResponseWriter, r *http.Request) (*multipart.FileHeader, bool) {
+	var respn itmodel.Response
+	//tambahan 1 MB untuk boundary multipart dan field lain seperti caption
+	r.Body = http.MaxBytesReader(w, r.Body, config.GambarMaxSize+1<<20)
 	_, header, err := r.FormFile("img")
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) || (err == nil && header.Size > config.GambarMaxSize) {
+		respn.Response = "ukuran gambar maksimal " + strconv.FormatInt(config.GambarMaxSize>>20, 10) + " MB"
+		helper.WriteJSON(w, http.StatusRequestEntityTooLarge, respn)
+		return nil, false
+	}
 	if err != nil {
 		fmt.Println("Error parsing form file:", err)
 		respn.Response = err.Error()
 		helper.WriteJSON(w, http.StatusBadRequest, respn)
-		return
+		return nil, false
 	}
+	return header, true
+}
 
-	folder := helper.GetParam(r)
-	var pathFile string
-	if folder != "" {
-		pathFile = folder + "/" + header.Filename
-	} else {
-		pathFile = header.Filename
+// unggahGambar mengencode ulang file gambar menjadi varian web dan thumb lalu mengunggah semuanya ke folder di repo filegambar.
+// Jenis file ditentukan dari isinya, nama file dari client diabaikan dan diganti hash isi supaya upload tidak saling menimpa.
+// Hasilnya path tiap varian di repo, jika gagal respon error sudah ditulis.
+func unggahGambar(w http.ResponseWriter, r *http.Request, header *multipart.FileHeader, folder string) (map[string]string, bool) {
+	var respn itmodel.Response
+	file, err := header.Open()
+	if err != nil {
+		respn.Response = err.Error()
+		helper.WriteJSON(w, http.StatusBadRequest, respn)
+		return nil, false
 	}
-
-	gh, err := atdb.GetOneDoc[model.Ghcreates](config.Mongoconn, "github", bson.M{})
+	defer file.Close()
+	data, err := io.ReadAll(file)
 	if err != nil {
-		fmt.Println("Error fetching GitHub credentials:", err)
-		respn.Info = helper.GetSecretFromHeader(r)
 		respn.Response = err.Error()
-		helper.WriteJSON(w, http.StatusConflict, respn)
-		return
+		helper.WriteJSON(w, http.StatusBadRequest, respn)
+		return nil, false
 	}
-
-	content, _, err := ghupload.GithubUpload(gh.GitHubAccessToken, gh.GitHubAuthorName, gh.GitHubAuthorEmail, header, "parkirgratis", "filegambar", pathFile, false)
+	if mime := http.DetectContentType(data); !slices.Contains(config.GambarMIME, mime) {
+		respn.Response = "tipe file " + mime + " tidak diterima, hanya " + strings.Join(config.GambarMIME, ", ")
+		helper.WriteJSON(w, http.StatusUnsupportedMediaType, respn)
+		return nil, false
+	}
+	varian, err := gambar.Proses(data)
 	if err != nil {
-		fmt.Println("Error uploading file to GitHub:", err)
-		respn.Info = "gagal upload github"
 		respn.Response = err.Error()
-		helper.WriteJSON(w, http.StatusEarlyHints, respn)
-		return
+		helper.WriteJSON(w, http.StatusUnprocessableEntity, respn)
+		return nil, false
 	}
-	if content == nil || content.Content == nil {
-		fmt.Println("Error: content or content.Content is nil")
-		respn.Response = "Error uploading file"
+
+	gh, err := atdb.GetOneDoc[model.Ghcreates](config.Mongoconn, "github", bson.M{})
+	if err != nil {
+		fmt.Println("Error fetching GitHub credentials:", err)
+		respn.Info = helper.GetSecretFromHeader(r)
+		respn.Response = err.Error()
 		helper.WriteJSON(w, http.StatusInternalServerError, respn)
-		return
+		return nil, false
 	}
 
-	respn.Info = *content.Content.Name
-	respn.Response = *content.Content.Path
-	helper.WriteJSON(w, http.StatusOK, respn)
-	fmt.Println("File upload process completed successfully")
+	hash := sha256.Sum256(data)
+	nama := hex.EncodeToString(hash[:16])
+	paths := map[string]string{}
+	for _, v := range varian {
+		pathFile := nama + "-" + v.Nama + gambar.Ext
+		if folder != "" {
+			pathFile = folder + "/" + pathFile
+		}
+		content, ghResp, err := ghupload.GithubUploadBytes(gh.GitHubAccessToken, gh.GitHubAuthorName, gh.GitHubAuthorEmail, v.Data, "parkirgratis", "filegambar", pathFile, false)
+		//github menolak dengan 422 jika path sudah ada, karena nama berasal dari hash isinya sama dengan upload sebelumnya
+		if err != nil && ghResp != nil && ghResp.StatusCode == http.StatusUnprocessableEntity {
+			paths[v.Nama] = pathFile
+			continue
+		}
+		if err != nil {
+			fmt.Println("Error uploading file to GitHub:", err)
+			respn.Info = "gagal upload github"
+			respn.Response = err.Error()
+			helper.WriteJSON(w, http.StatusBadGateway, respn)
+			return nil, false
+		}
+		if content == nil || content.Content == nil {
+			fmt.Println("Error: content or content.Content is nil")
+			respn.Response = "Error uploading file"
+			helper.WriteJSON(w, http.StatusInternalServerError, respn)
+			return nil, false
+		}
+		paths[v.Nama] = *content.Content.Path
+	}
+	return paths, true
 }
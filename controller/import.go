@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/geo"
+	"github.com/gocroot/helper/validasi"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// alias nama kolom CSV yang diterima untuk tiap field model.Tempat
+var importKolom = map[string]string{
+	"nama_tempat": "nama_tempat",
+	"nama":        "nama_tempat",
+	"lokasi":      "lokasi",
+	"alamat":      "lokasi",
+	"fasilitas":   "fasilitas",
+	"lon":         "lon",
+	"lng":         "lon",
+	"longitude":   "lon",
+	"lat":         "lat",
+	"latitude":    "lat",
+	"gambar":      "gambar",
+}
+
+// PostImportTempat menerima upload CSV di field "file", ?dry_run=true hanya memvalidasi tanpa menyimpan
+func PostImportTempat(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	req.Body = http.MaxBytesReader(respw, req.Body, config.ImportMaxSize)
+	file, _, err := req.FormFile("file")
+	if err != nil {
+		resp.Response = "File CSV tidak ditemukan di field file: " + err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	defer file.Close()
+
+	rows, err := parseImportCSV(file)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+
+	report := model.ImportReport{
+		DryRun: req.URL.Query().Get("dry_run") == "true",
+		Total:  len(rows),
+	}
+	var docs []interface{}
+	var valid []model.ImportRow
+	for _, row := range rows {
+		if row.Valid {
+			report.Valid++
+			if !report.DryRun {
+				isiWilayah(&row.Data)
+			}
+			docs = append(docs, row.Data)
+			valid = append(valid, row)
+		} else {
+			report.Skipped = append(report.Skipped, row)
+		}
+	}
+	if report.DryRun {
+		report.Rows = rows
+		report.Skipped = nil
+		helper.WriteJSON(respw, http.StatusOK, report)
+		return
+	}
+
+	for start := 0; start < len(docs); start += config.ImportBatchSize {
+		end := min(start+config.ImportBatchSize, len(docs))
+		//unordered supaya satu baris yang ditolak tidak menghentikan sisa batch
+		ids, err := atdb.InsertManyDocs(config.Mongoconn, "tempat", docs[start:end], options.InsertMany().SetOrdered(false))
+		gagal := map[int]string{}
+		var bwe mongo.BulkWriteException
+		if errors.As(err, &bwe) {
+			for _, we := range bwe.WriteErrors {
+				gagal[we.Index] = we.Message
+			}
+			if bwe.WriteConcernError == nil {
+				err = nil
+			}
+		} else if err != nil {
+			//tanpa BulkWriteException tidak bisa diketahui baris mana yang tersimpan
+			ids = nil
+		}
+
+		var revisi []interface{}
+		for i := range docs[start:end] {
+			row := valid[start+i]
+			pesan, ditolak := gagal[i]
+			if i >= len(ids) || ditolak {
+				if !ditolak && err != nil {
+					pesan = err.Error()
+				}
+				row.Errors = append(row.Errors, pesan)
+				report.Failed = append(report.Failed, row)
+				continue
+			}
+			id := ids[i].(primitive.ObjectID)
+			report.Inserted = append(report.Inserted, id.Hex())
+			tempat := row.Data
+			tempat.ID = id
+			revisi = append(revisi, revisiTempat(req, AksiCreate, nil, &tempat))
+		}
+		if len(revisi) > 0 {
+			if _, errRevisi := atdb.InsertManyDocs(config.Mongoconn, "tempat_revisi", revisi); errRevisi != nil && err == nil {
+				err = errRevisi
+			}
+		}
+		if err != nil {
+			report.Error = err.Error()
+			helper.WriteJSON(respw, http.StatusInternalServerError, report)
+			return
+		}
+	}
+	helper.WriteJSON(respw, http.StatusOK, report)
+}
+
+func parseImportCSV(r io.Reader) (rows []model.ImportRow, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		err = errors.New("CSV kosong atau header tidak terbaca")
+		return
+	}
+	kolom := map[int]string{}
+	ada := map[string]bool{}
+	for i, name := range header {
+		field, ok := importKolom[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))]
+		if ok {
+			kolom[i] = field
+			ada[field] = true
+		}
+	}
+	for _, wajib := range []string{"nama_tempat", "lon", "lat"} {
+		if !ada[wajib] {
+			err = errors.New("CSV wajib memiliki kolom " + wajib)
+			return
+		}
+	}
+
+	baris := 1
+	for {
+		record, errRead := reader.Read()
+		if errRead == io.EOF {
+			break
+		}
+		baris++
+		if errRead != nil {
+			rows = append(rows, model.ImportRow{Baris: baris, Errors: []string{errRead.Error()}})
+			continue
+		}
+		values := map[string]string{}
+		for i, val := range record {
+			if field, ok := kolom[i]; ok {
+				values[field] = strings.TrimSpace(val)
+			}
+		}
+		rows = append(rows, importRow(baris, values))
+	}
+	return
+}
+
+func importRow(baris int, values map[string]string) (row model.ImportRow) {
+	row.Baris = baris
+	row.Data = model.Tempat{
+		Nama_Tempat: values["nama_tempat"],
+		Lokasi:      values["lokasi"],
+		Fasilitas:   values["fasilitas"],
+		Gambar:      values["gambar"],
+	}
+
+	lon, errLon := strconv.ParseFloat(values["lon"], 64)
+	lat, errLat := strconv.ParseFloat(values["lat"], 64)
+	if errLon != nil || errLat != nil {
+		row.Errors = append(row.Errors, "lon/lat bukan angka")
+	} else {
+		row.Data.Lon, row.Data.Lat = lon, lat
+	}
+	for _, fe := range validasi.Tempat(&row.Data) {
+		//koordinat yang gagal di-parse sudah dilaporkan di atas
+		if errLon != nil || errLat != nil {
+			if fe.Field == "lon" || fe.Field == "lat" || fe.Field == "koordinat" {
+				continue
+			}
+		}
+		row.Errors = append(row.Errors, fe.Field+": "+fe.Pesan)
+	}
+	row.Valid = len(row.Errors) == 0
+	if row.Valid {
+		row.Data.Location = geo.NewPoint(lon, lat)
+		if row.Data.Gambar != "" {
+			row.Data.Gambar = config.GambarBaseURL + strings.TrimPrefix(row.Data.Gambar, config.GambarBaseURL)
+			row.Data.Galeri = rapikanCover([]model.Foto{fotoGambar(row.Data.Gambar, "", time.Now().UTC())})
+		}
+	}
+	return
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gocroot/config"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// kendaraanFilter menerima "motor,mobil", tempat yang belum punya data untuk jenis itu tetap ikut sebagai unknown
+func kendaraanFilter(param string) (conds []bson.M, err error) {
+	for _, jenis := range strings.Split(param, ",") {
+		jenis = strings.ToLower(strings.TrimSpace(jenis))
+		if jenis == "" {
+			continue
+		}
+		if !slices.Contains(config.JenisKendaraan, jenis) {
+			err = fmt.Errorf("kendaraan harus salah satu dari %s", strings.Join(config.JenisKendaraan, ", "))
+			return
+		}
+		conds = append(conds, bson.M{"$or": bson.A{
+			bson.M{"kendaraan." + jenis + ".diizinkan": true},
+			bson.M{"kendaraan." + jenis: bson.M{"$exists": false}},
+		}})
+	}
+	return
+}
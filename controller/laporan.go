@@ -0,0 +1,314 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/validasi"
+	"github.com/gocroot/middleware"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	LaporanOpen      = "open"
+	LaporanResolved  = "resolved"
+	LaporanDismissed = "dismissed"
+)
+
+// PostLaporan menerima laporan publik, phone atau session wajib diisi seperti ulasan dan satu pelapor hanya boleh punya satu laporan open per tempat
+func PostLaporan(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	var body struct {
+		TempatID string `json:"tempat_id"`
+		Kategori string `json:"kategori"`
+		Komentar string `json:"komentar"`
+		Foto     string `json:"foto"`
+		Phone    string `json:"phone"`
+		Session  string `json:"session"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	laporan := model.Laporan{
+		Kategori:  strings.ToLower(strings.TrimSpace(body.Kategori)),
+		Komentar:  strings.TrimSpace(body.Komentar),
+		Foto:      body.Foto,
+		Status:    LaporanOpen,
+		CreatedAt: time.Now().UTC(),
+	}
+	errs := validasi.Laporan(&laporan)
+	tempatID, err := primitive.ObjectIDFromHex(body.TempatID)
+	if err != nil {
+		errs.Add("tempat_id", "tidak valid")
+	}
+	pelapor, ok := identitasPengguna(body.Phone, body.Session)
+	if !ok {
+		errs.Add("phone", "isi nomor telepon yang valid atau session")
+	}
+	laporan.Pelapor = pelapor
+	if len(errs) > 0 {
+		writeValidasi(respw, errs)
+		return
+	}
+	if _, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"_id": tempatID})); err != nil {
+		writeTempatError(respw, err)
+		return
+	}
+	laporan.TempatID = tempatID
+	if laporan.Foto != "" {
+		laporan.Foto = config.GambarBaseURL + strings.TrimPrefix(laporan.Foto, config.GambarBaseURL)
+	}
+
+	//index unik parsial tempat_id+pelapor untuk status open menolak laporan kedua, termasuk dua request yang datang bersamaan
+	id, err := atdb.InsertOneDoc(config.Mongoconn, "laporan", laporan)
+	if mongo.IsDuplicateKeyError(err) {
+		resp.Response = "Laporan Anda untuk tempat ini masih diproses"
+		helper.WriteJSON(respw, http.StatusConflict, resp)
+		return
+	}
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	laporan.ID = id.(primitive.ObjectID)
+	if err := perbaruiRingkasanLaporan(tempatID); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusCreated, laporan)
+}
+
+// GetAntrianLaporan menampilkan laporan open dikelompokkan per tempat, tempat dengan laporan terbanyak lebih dulu
+func GetAntrianLaporan(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	laporan, err := atdb.GetAllDoc[[]model.Laporan](config.Mongoconn, "laporan", bson.M{"status": LaporanOpen}, opts)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	perTempat := map[primitive.ObjectID][]model.Laporan{}
+	ids := bson.A{}
+	for _, l := range laporan {
+		if _, ada := perTempat[l.TempatID]; !ada {
+			ids = append(ids, l.TempatID)
+		}
+		perTempat[l.TempatID] = append(perTempat[l.TempatID], l)
+	}
+	antrian := []model.AntrianLaporan{}
+	if len(ids) > 0 {
+		tempat, err := atdb.GetAllDoc[[]model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"_id": bson.M{"$in": ids}}))
+		if err != nil {
+			resp.Response = err.Error()
+			helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+			return
+		}
+		for _, t := range tempat {
+			antrian = append(antrian, model.AntrianLaporan{Tempat: t, Laporan: perTempat[t.ID]})
+		}
+	}
+	sort.SliceStable(antrian, func(i, j int) bool { return len(antrian[i].Laporan) > len(antrian[j].Laporan) })
+	helper.WriteJSON(respw, http.StatusOK, antrian)
+}
+
+// PostResolveLaporan menandai laporan ids sudah ditindaklanjuti
+func PostResolveLaporan(respw http.ResponseWriter, req *http.Request) {
+	tutupLaporan(respw, req, LaporanResolved)
+}
+
+// PostDismissLaporan menolak laporan ids yang tidak terbukti
+func PostDismissLaporan(respw http.ResponseWriter, req *http.Request) {
+	tutupLaporan(respw, req, LaporanDismissed)
+}
+
+func tutupLaporan(respw http.ResponseWriter, req *http.Request, status string) {
+	var resp itmodel.Response
+	var body struct {
+		IDs     []string `json:"ids"`
+		Catatan string   `json:"catatan"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	ids := bson.A{}
+	for _, idHex := range body.IDs {
+		id, err := primitive.ObjectIDFromHex(idHex)
+		if err != nil {
+			resp.Response = "ID laporan tidak valid: " + idHex
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		resp.Response = "ids wajib diisi"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	filter := bson.M{"_id": bson.M{"$in": ids}, "status": LaporanOpen}
+	laporan, err := atdb.GetAllDoc[[]model.Laporan](config.Mongoconn, "laporan", filter)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	n, err := putuskanLaporan(req, filter, status, body.Catatan)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	tempatIDs := map[primitive.ObjectID]bool{}
+	for _, l := range laporan {
+		tempatIDs[l.TempatID] = true
+	}
+	for tempatID := range tempatIDs {
+		if err := perbaruiRingkasanLaporan(tempatID); err != nil {
+			resp.Response = err.Error()
+			helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+			return
+		}
+	}
+	resp.Response = "Laporan " + status
+	resp.Info = strconv.FormatInt(n, 10)
+	helper.WriteJSON(respw, http.StatusOK, resp)
+}
+
+// PostTerapkanLaporan mengubah status tempat sesuai hasil triase lalu me-resolve semua laporan open tempat itu.
+// Status kosong atau "aktif" mengembalikan tempat menjadi aktif.
+func PostTerapkanLaporan(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	var body struct {
+		TempatID string `json:"tempat_id"`
+		Status   string `json:"status"`
+		Catatan  string `json:"catatan"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	if body.Status == "aktif" {
+		body.Status = ""
+	}
+	tempatID, err := primitive.ObjectIDFromHex(body.TempatID)
+	if err != nil {
+		resp.Response = "tempat_id tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	sebelum, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"_id": tempatID}))
+	if err != nil {
+		writeTempatError(respw, err)
+		return
+	}
+	if body.Status != "" && !slices.Contains(config.StatusTempat, body.Status) {
+		writeValidasi(respw, []model.FieldError{{Field: "status", Pesan: "harus aktif atau salah satu dari " + strings.Join(config.StatusTempat, ", ")}})
+		return
+	}
+	sesudah := sebelum
+	sesudah.Status = body.Status
+
+	update := bson.M{"$set": bson.M{"status": body.Status}, "$inc": bson.M{"versi": 1}}
+	if body.Status == "" {
+		update = bson.M{"$unset": bson.M{"status": ""}, "$inc": bson.M{"versi": 1}}
+	}
+	if _, err := atdb.UpdateDoc(config.Mongoconn, "tempat", bson.M{"_id": tempatID}, update); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	sesudah.Versi++
+	if err := catatRevisi(req, AksiUpdate, &sebelum, &sesudah); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if _, err := putuskanLaporan(req, bson.M{"tempat_id": tempatID, "status": LaporanOpen}, LaporanResolved, body.Catatan); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if err := perbaruiRingkasanLaporan(tempatID); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	tempat, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", bson.M{"_id": tempatID})
+	if err != nil {
+		writeTempatError(respw, err)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, tempat)
+}
+
+func putuskanLaporan(req *http.Request, filter bson.M, status, catatan string) (int64, error) {
+	set := bson.M{
+		"status":        status,
+		"diputuskan_by": middleware.GetAdminID(req),
+		"diputuskan_at": time.Now().UTC(),
+	}
+	if catatan = strings.TrimSpace(catatan); catatan != "" {
+		set["catatan"] = catatan
+	}
+	result, err := atdb.UpdateManyDoc(config.Mongoconn, "laporan", filter, bson.M{"$set": set})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// perbaruiRingkasanLaporan menghitung ulang laporan open per kategori dan menandai tempat yang pelapor berbedanya mencapai config.LaporanAmbang.
+// Laporan lama tanpa pelapor ikut dihitung per kategori tetapi tidak ke ambang.
+func perbaruiRingkasanLaporan(tempatID primitive.ObjectID) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"tempat_id": tempatID, "status": LaporanOpen}}},
+		{{Key: "$group", Value: bson.M{"_id": "$kategori", "jumlah": bson.M{"$sum": 1}, "pelapor": bson.M{"$addToSet": "$pelapor"}}}},
+	}
+	hasil, err := atdb.GetAggregateDoc[[]struct {
+		Kategori string   `bson:"_id"`
+		Jumlah   int      `bson:"jumlah"`
+		Pelapor  []string `bson:"pelapor"`
+	}](config.Mongoconn, "laporan", pipeline)
+	if err != nil {
+		return err
+	}
+	ringkasan := model.RingkasanLaporan{Kategori: map[string]int{}}
+	pelapor := map[string]bool{}
+	for _, h := range hasil {
+		ringkasan.Kategori[h.Kategori] = h.Jumlah
+		ringkasan.Open += h.Jumlah
+		for _, p := range h.Pelapor {
+			if p != "" {
+				pelapor[p] = true
+			}
+		}
+	}
+	ringkasan.Pelapor = len(pelapor)
+	update := bson.M{"$unset": bson.M{"laporan": "", "ditandai": ""}}
+	if ringkasan.Open > 0 {
+		update = bson.M{"$set": bson.M{"laporan": ringkasan, "ditandai": ringkasan.Pelapor >= config.LaporanAmbang}}
+	}
+	_, err = atdb.UpdateDoc(config.Mongoconn, "tempat", bson.M{"_id": tempatID}, update)
+	return err
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/geo"
+	"github.com/gocroot/helper/teks"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetLokasiNearby mengembalikan tempat terdekat dari lat/lon, urut dari yang paling dekat
+func GetLokasiNearby(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	query := req.URL.Query()
+
+	lat, errLat := strconv.ParseFloat(query.Get("lat"), 64)
+	lon, errLon := strconv.ParseFloat(query.Get("lon"), 64)
+	if errLat != nil || errLon != nil || !geo.ValidLonLat(lon, lat) {
+		resp.Response = "Parameter lat dan lon wajib diisi dengan koordinat yang valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+
+	radius := config.NearbyDefaultRadius
+	if query.Get("radius") != "" {
+		r, err := strconv.ParseFloat(query.Get("radius"), 64)
+		if err != nil || r <= 0 {
+			resp.Response = "Parameter radius harus angka positif dalam meter"
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		radius = min(r, config.NearbyMaxRadius)
+	}
+
+	limit := config.NearbyDefaultLimit
+	if query.Get("limit") != "" {
+		l, err := strconv.ParseInt(query.Get("limit"), 10, 64)
+		if err != nil || l <= 0 {
+			resp.Response = "Parameter limit harus bilangan bulat positif"
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		limit = min(l, config.NearbyMaxLimit)
+	}
+
+	conds, err := tempatFilters(query)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	buka, err := parseWaktuBuka(query)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+
+	// $geoNear sudah mengurutkan hasil berdasarkan jarak
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.D{
+			{Key: "near", Value: geo.NewPoint(lon, lat)},
+			{Key: "distanceField", Value: "jarak"},
+			{Key: "maxDistance", Value: radius},
+			{Key: "spherical", Value: true},
+			{Key: "query", Value: andFilter(conds...)},
+		}}},
+	}
+	//saringan jam buka dihitung di Go, jadi $limit hanya dipasang jika tidak ada tempat yang dibuang
+	if !buka.saring {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+	tempat, err := atdb.GetFilteredAggregateDoc(config.Mongoconn, "tempat", pipeline, limit, func(t *model.TempatJarak) bool { return buka.terapkan(&t.Tempat) })
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, tempat)
+}
+
+// bboxFilter memakai rentang lon/lat biasa supaya viewport selebar apapun tetap persegi panjang di peta
+func bboxFilter(box geo.BBox) bson.M {
+	lat := bson.M{"$gte": box.MinLat, "$lte": box.MaxLat}
+	if box.CrossesAntimeridian() {
+		return bson.M{
+			"lat": lat,
+			"$or": bson.A{
+				bson.M{"lon": bson.M{"$gte": box.MinLon}},
+				bson.M{"lon": bson.M{"$lte": box.MaxLon}},
+			},
+		}
+	}
+	return bson.M{
+		"lat": lat,
+		"lon": bson.M{"$gte": box.MinLon, "$lte": box.MaxLon},
+	}
+}
+
+// GetLokasiSearch mencari tempat berdasarkan nama, alamat dan fasilitas, urut dari yang paling relevan
+func GetLokasiSearch(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	query := req.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		resp.Response = "Parameter q wajib diisi"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+
+	limit := config.SearchDefaultLimit
+	if query.Get("limit") != "" {
+		l, err := strconv.ParseInt(query.Get("limit"), 10, 64)
+		if err != nil || l <= 0 {
+			resp.Response = "Parameter limit harus bilangan bulat positif"
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		limit = min(l, config.SearchMaxLimit)
+	}
+
+	conds, err := tempatFilters(query)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	buka, err := parseWaktuBuka(query)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	filter := andFilter(append(conds, bson.M{"$text": bson.M{"$search": q}})...)
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"skor": score}).
+		SetSort(bson.D{{Key: "skor", Value: score}})
+	if !buka.saring {
+		opts.SetLimit(limit)
+	}
+	tempat, err := atdb.GetFilteredDoc(config.Mongoconn, "tempat", filter, limit, func(t *model.TempatSearch) bool { return buka.terapkan(&t.Tempat) }, opts)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+
+	terms := teks.SearchTerms(q)
+	for i := range tempat {
+		highlight := map[string]string{}
+		for field, text := range map[string]string{
+			"nama_tempat": tempat[i].Nama_Tempat,
+			"lokasi":      tempat[i].Lokasi,
+			"fasilitas":   tempat[i].Fasilitas,
+		} {
+			if snippet := teks.Highlight(text, terms, config.SearchSnippetRadius); snippet != "" {
+				highlight[field] = snippet
+			}
+		}
+		tempat[i].Highlight = highlight
+	}
+	helper.WriteJSON(respw, http.StatusOK, tempat)
+}
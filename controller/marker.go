@@ -0,0 +1,351 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/geo"
+	"github.com/gocroot/helper/validasi"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// markerRequest adalah payload create dan update marker, field kosong tidak diubah saat update
+type markerRequest struct {
+	Lon      *float64 `json:"lon"`
+	Lat      *float64 `json:"lat"`
+	TempatID *string  `json:"tempat_id"` //string kosong melepas tautan ke tempat
+}
+
+func GetMarkers(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	filter := bson.M{}
+	if tempatID := req.URL.Query().Get("tempat_id"); tempatID != "" {
+		id, err := primitive.ObjectIDFromHex(tempatID)
+		if err != nil {
+			resp.Response = "tempat_id tidak valid"
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		filter["tempat_id"] = id
+	}
+	markers, err := atdb.GetAllDoc[[]model.Marker](config.Mongoconn, "penanda", filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if markers == nil {
+		markers = []model.Marker{}
+	}
+	helper.WriteJSON(respw, http.StatusOK, markers)
+}
+
+func GetMarkerByID(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	id, err := primitive.ObjectIDFromHex(helper.GetParam(req))
+	if err != nil {
+		resp.Response = "ID marker tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	marker, err := atdb.GetOneDoc[model.Marker](config.Mongoconn, "penanda", bson.M{"_id": id})
+	if err == mongo.ErrNoDocuments {
+		resp.Response = "Marker tidak ditemukan"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, marker)
+}
+
+func PostMarker(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	var body markerRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	var errs validasi.Errors
+	if body.Lon == nil {
+		errs.Add("lon", "wajib diisi")
+	}
+	if body.Lat == nil {
+		errs.Add("lat", "wajib diisi")
+	}
+	if len(errs) > 0 {
+		writeValidasi(respw, errs)
+		return
+	}
+	if validasi.Titik(&errs, "", *body.Lon, *body.Lat); len(errs) > 0 {
+		writeValidasi(respw, errs)
+		return
+	}
+	marker := newMarker(*body.Lon, *body.Lat)
+	if body.TempatID != nil && *body.TempatID != "" {
+		tempatID, err := tempatIDTerdaftar(*body.TempatID)
+		if err != nil {
+			resp.Response = err.Error()
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		marker.TempatID = &tempatID
+	}
+
+	insertedID, err := atdb.InsertOneDoc(config.Mongoconn, "penanda", marker)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	marker.ID = insertedID.(primitive.ObjectID)
+	helper.WriteJSON(respw, http.StatusCreated, marker)
+}
+
+func PutMarker(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	id, err := primitive.ObjectIDFromHex(helper.GetParam(req))
+	if err != nil {
+		resp.Response = "ID marker tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	var body markerRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	current, err := atdb.GetOneDoc[model.Marker](config.Mongoconn, "penanda", bson.M{"_id": id})
+	if err == mongo.ErrNoDocuments {
+		resp.Response = "Marker tidak ditemukan"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+
+	lon, lat := current.Lon, current.Lat
+	if body.Lon != nil {
+		lon = *body.Lon
+	}
+	if body.Lat != nil {
+		lat = *body.Lat
+	}
+	var errs validasi.Errors
+	if validasi.Titik(&errs, "", lon, lat); len(errs) > 0 {
+		writeValidasi(respw, errs)
+		return
+	}
+	update := bson.M{"$set": bson.M{
+		"lon":        lon,
+		"lat":        lat,
+		"location":   geo.NewPoint(lon, lat),
+		"updated_at": time.Now().UTC(),
+	}}
+	if body.TempatID != nil {
+		if *body.TempatID == "" {
+			update["$unset"] = bson.M{"tempat_id": ""}
+		} else {
+			tempatID, err := tempatIDTerdaftar(*body.TempatID)
+			if err != nil {
+				resp.Response = err.Error()
+				helper.WriteJSON(respw, http.StatusBadRequest, resp)
+				return
+			}
+			update["$set"].(bson.M)["tempat_id"] = tempatID
+		}
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var marker model.Marker
+	err = config.Mongoconn.Collection("penanda").FindOneAndUpdate(req.Context(), bson.M{"_id": id}, update, opts).Decode(&marker)
+	if err == mongo.ErrNoDocuments {
+		resp.Response = "Marker tidak ditemukan"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, marker)
+}
+
+func DeleteMarker(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	id, err := primitive.ObjectIDFromHex(helper.GetParam(req))
+	if err != nil {
+		resp.Response = "ID marker tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	deletedCount, err := atdb.DeleteOneDoc(config.Mongoconn, "penanda", bson.M{"_id": id})
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if deletedCount == 0 {
+		resp.Response = "Marker tidak ditemukan"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+		return
+	}
+	resp.Response = "Marker dihapus"
+	helper.WriteJSON(respw, http.StatusOK, resp)
+}
+
+// PostMigrasiMarker memecah dokumen marker lama menjadi satu dokumen per marker di koleksi penanda.
+// Marker ditautkan ke tempat yang lon/lat-nya sama persis, dokumen lama ditandai migrated_at supaya tidak diproses dua kali.
+func PostMigrasiMarker(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	legacy, err := legacyKoordinat()
+	if err == mongo.ErrNoDocuments {
+		resp.Response = "Dokumen marker lama tidak ditemukan"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if legacy.MigratedAt != nil {
+		resp.Response = "Marker lama sudah dimigrasi pada " + legacy.MigratedAt.Format(time.RFC3339)
+		helper.WriteJSON(respw, http.StatusConflict, resp)
+		return
+	}
+	report, err := migrasiMarker(req.Context(), legacy)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, report)
+}
+
+var markerMigrasiOnce sync.Once
+
+// MigrasiMarkerLama dijalankan sekali per instance dari route, memindah dokumen marker lama ke penanda sebelum request pertama dilayani
+func MigrasiMarkerLama() {
+	markerMigrasiOnce.Do(func() {
+		if config.ErrorMongoconn != nil {
+			return
+		}
+		if err := pastikanMarkerMigrasi(context.Background()); err != nil {
+			log.Println("MigrasiMarkerLama:", err)
+		}
+	})
+}
+
+// pastikanMarkerMigrasi menjalankan migrasi marker lama jika belum, dipanggil juga sebelum endpoint koordinat lama menulis
+// supaya migrasi yang gagal saat instance mulai dicoba ulang sebelum ada perubahan
+func pastikanMarkerMigrasi(ctx context.Context) error {
+	legacy, err := legacyKoordinat()
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if legacy.MigratedAt != nil {
+		return nil
+	}
+	_, err = migrasiMarker(ctx, legacy)
+	return err
+}
+
+// migrasiMarker meng-upsert tiap marker lama dengan kunci legacy_idx sehingga aman diulang jika gagal di tengah jalan,
+// migrated_at baru ditulis setelah semua marker tersimpan
+func migrasiMarker(ctx context.Context, legacy model.Koordinat) (model.MigrasiMarker, error) {
+	report := model.MigrasiMarker{Total: len(legacy.Markers), Inserted: []string{}}
+	opts := options.Update().SetUpsert(true)
+	for i, pair := range legacy.Markers {
+		if len(pair) != 2 || !geo.ValidLonLat(pair[0], pair[1]) {
+			report.Skipped++
+			continue
+		}
+		marker := newMarker(pair[0], pair[1])
+		marker.LegacyIdx = &i
+		tempat, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"lon": pair[0], "lat": pair[1]}))
+		if err == nil {
+			marker.TempatID = &tempat.ID
+			report.Tertaut++
+		}
+		result, err := config.Mongoconn.Collection("penanda").UpdateOne(ctx, bson.M{"legacy_idx": i}, bson.M{"$setOnInsert": marker}, opts)
+		if mongo.IsDuplicateKeyError(err) {
+			//migrasi lain sudah menyisipkan marker ini lebih dulu
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+		if id, ok := result.UpsertedID.(primitive.ObjectID); ok {
+			report.Inserted = append(report.Inserted, id.Hex())
+		}
+	}
+	if _, err := atdb.UpdateDoc(config.Mongoconn, "marker", bson.M{"_id": legacy.ID}, bson.M{"$set": bson.M{"migrated_at": time.Now().UTC()}}); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func newMarker(lon, lat float64) model.Marker {
+	now := time.Now().UTC()
+	return model.Marker{
+		Lon:       lon,
+		Lat:       lat,
+		Location:  geo.NewPoint(lon, lat),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func legacyKoordinat() (model.Koordinat, error) {
+	id, err := primitive.ObjectIDFromHex(config.LegacyMarkerID)
+	if err != nil {
+		return model.Koordinat{}, err
+	}
+	return atdb.GetOneDoc[model.Koordinat](config.Mongoconn, "marker", bson.M{"_id": id})
+}
+
+// markerKoordinat menyusun bentuk lama {markers: [[lon, lat], ...]} untuk client yang belum pindah ke per-marker
+func markerKoordinat(markers []model.Marker) model.Koordinat {
+	koordinat := model.Koordinat{Markers: make([][]float64, 0, len(markers))}
+	for _, m := range markers {
+		koordinat.Markers = append(koordinat.Markers, []float64{m.Lon, m.Lat})
+	}
+	return koordinat
+}
+
+func tempatIDTerdaftar(hex string) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return id, errors.New("tempat_id tidak valid")
+	}
+	if _, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"_id": id})); err != nil {
+		return id, errors.New("tempat_id tidak ditemukan")
+	}
+	return id, nil
+}
@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/geo"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// field yang diisi server sehingga tidak bisa diubah lewat PATCH
+var tempatFieldServer = map[string]bool{
+	"_id":         true,
+	"location":    true,
+	"wilayah":     true,
+	"status_buka": true,
+	"deleted_at":  true,
+	"deleted_by":  true,
+	"versi":       true,
+	"rating":      true,
+	"laporan":     true,
+	"ditandai":    true,
+	"galeri":      true,
+	"gambar":      true, //salinan cover galeri, diubah lewat /admin/galeri
+	"status":      true, //diubah lewat triase laporan
+}
+
+// PatchTempat menerapkan JSON Merge Patch (RFC 7396), null menghapus field dan nilai nol seperti lon 0 tetap disimpan
+func PatchTempat(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	id, err := primitive.ObjectIDFromHex(helper.GetParam(req))
+	if err != nil {
+		resp.Response = "ID tempat tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	var patch map[string]interface{}
+	if err := json.NewDecoder(req.Body).Decode(&patch); err != nil || patch == nil {
+		resp.Response = "Body harus berupa objek JSON merge patch"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	for field, value := range patch {
+		if !tempatFields[field] || tempatFieldServer[field] {
+			resp.Response = "field tidak bisa diubah: " + field
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		if value == nil && (field == "lon" || field == "lat") {
+			resp.Response = "lon dan lat tidak bisa dihapus"
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+	}
+	versi, ok := syaratVersi(respw, req)
+	if !ok {
+		return
+	}
+
+	sebelum, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"_id": id}))
+	if err != nil {
+		writeTempatError(respw, err)
+		return
+	}
+	if sebelum.Versi != versi {
+		writeKonflikVersi(respw, sebelum)
+		return
+	}
+
+	hasil, err := terapkanMergePatch(sebelum, patch)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	errs, err := validasiTempat(&hasil)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if len(errs) > 0 {
+		writeValidasi(respw, errs)
+		return
+	}
+
+	//nilai $set diambil dari hasil merge supaya tipe dan isi nested object sama dengan model
+	set, unset := bson.M{}, bson.M{}
+	for field, value := range patch {
+		if value == nil {
+			unset[field] = ""
+		} else {
+			set[field] = nilaiFieldTempat(hasil, field)
+		}
+	}
+	_, lonBerubah := patch["lon"]
+	_, latBerubah := patch["lat"]
+	if lonBerubah || latBerubah {
+		set["location"] = geo.NewPoint(hasil.Lon, hasil.Lat)
+	}
+	update := bson.M{"$inc": bson.M{"versi": 1}}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	result, err := atdb.UpdateDoc(config.Mongoconn, "tempat", versiFilter(tempatAktif(bson.M{"_id": id}), sebelum.Versi), update)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if result.MatchedCount == 0 {
+		writeTempatKonflik(respw, id)
+		return
+	}
+	if lonBerubah || latBerubah {
+		if err := perbaruiWilayah(id); err != nil {
+			resp.Response = err.Error()
+			helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+			return
+		}
+	}
+
+	sesudah, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", bson.M{"_id": id})
+	if err != nil {
+		writeTempatError(respw, err)
+		return
+	}
+	if err := catatRevisi(req, AksiUpdate, &sebelum, &sesudah); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	waktuBuka{at: time.Now()}.terapkan(&sesudah)
+	respw.Header().Set("ETag", etagTempat(sesudah))
+	helper.WriteJSON(respw, http.StatusOK, sesudah)
+}
+
+// terapkanMergePatch menggabungkan patch ke representasi JSON tempat lalu membacanya kembali sebagai model.Tempat
+func terapkanMergePatch(tempat model.Tempat, patch map[string]interface{}) (hasil model.Tempat, err error) {
+	raw, err := json.Marshal(tempat)
+	if err != nil {
+		return
+	}
+	var target map[string]interface{}
+	if err = json.Unmarshal(raw, &target); err != nil {
+		return
+	}
+	raw, err = json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return
+	}
+	//nilai nol yang dikirim eksplisit (lon 0, gambar "") tetap terbaca karena field hasil dimulai dari nol
+	err = json.Unmarshal(raw, &hasil)
+	return
+}
+
+// mergePatch mengikuti algoritma RFC 7396, objek digabung rekursif dan null menghapus key
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = mergePatch(targetObj[key], value)
+		}
+	}
+	return targetObj
+}
+
+// nilaiFieldTempat mengambil nilai field model.Tempat berdasarkan nama bson-nya
+func nilaiFieldTempat(t model.Tempat, field string) interface{} {
+	v := reflect.ValueOf(t)
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("bson"), ",")
+		if name == field {
+			return v.Field(i).Interface()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gocroot/model"
+)
+
+// kasus dari RFC 7396 Appendix A
+func TestMergePatch(t *testing.T) {
+	tests := []struct{ target, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		var target, patch, want interface{}
+		mustUnmarshal(t, tt.target, &target)
+		mustUnmarshal(t, tt.patch, &patch)
+		mustUnmarshal(t, tt.want, &want)
+		if got := mergePatch(target, patch); !reflect.DeepEqual(got, want) {
+			t.Errorf("mergePatch(%s, %s) = %v, want %s", tt.target, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestTerapkanMergePatch(t *testing.T) {
+	tempat := model.Tempat{
+		Nama_Tempat: "Parkir Monas",
+		Fasilitas:   "CCTV",
+		Lon:         106.8272,
+		Lat:         -6.1754,
+		JamBuka:     &model.JamBuka{Mingguan: []model.JadwalHarian{{Hari: 1, Buka: "08:00", Tutup: "17:00"}}},
+	}
+	var patch map[string]interface{}
+	mustUnmarshal(t, `{"lon":0,"fasilitas":null,"jam_buka":{"pengecualian":[{"tanggal":"2024-08-17","libur":true}]}}`, &patch)
+	hasil, err := terapkanMergePatch(tempat, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasil.Lon != 0 || hasil.Lat != tempat.Lat || hasil.Nama_Tempat != tempat.Nama_Tempat {
+		t.Errorf("lon 0 harus tersimpan dan field lain tetap, got lon %v lat %v nama %q", hasil.Lon, hasil.Lat, hasil.Nama_Tempat)
+	}
+	if hasil.Fasilitas != "" {
+		t.Errorf("fasilitas null harus terhapus, got %q", hasil.Fasilitas)
+	}
+	if hasil.JamBuka == nil || len(hasil.JamBuka.Mingguan) != 1 || len(hasil.JamBuka.Pengecualian) != 1 {
+		t.Errorf("jam_buka harus digabung rekursif, got %+v", hasil.JamBuka)
+	}
+	if len(tempat.JamBuka.Pengecualian) != 0 {
+		t.Error("tempat asal tidak boleh ikut berubah")
+	}
+	if got := nilaiFieldTempat(hasil, "nama_tempat"); got != "Parkir Monas" {
+		t.Errorf("nilaiFieldTempat nama_tempat = %v", got)
+	}
+	if got := nilaiFieldTempat(hasil, "tidak_ada"); got != nil {
+		t.Errorf("nilaiFieldTempat field tidak dikenal = %v, want nil", got)
+	}
+}
+
+func mustUnmarshal(t *testing.T, s string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		t.Fatalf("unmarshal %s: %v", s, err)
+	}
+}
+
+func TestPatchTempatFieldServer(t *testing.T) {
+	for _, body := range []string{
+		`{"status": "tutup"}`,
+		`{"status": null}`,
+		`{"gambar": "https://example.com/a.jpg"}`,
+		`{"versi": 9}`,
+		`{"ditandai": false}`,
+		`{"tidak_ada": 1}`,
+	} {
+		req := httptest.NewRequest(http.MethodPatch, "/data/tempat/000000000000000000000001", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		PatchTempat(rec, req)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "field tidak bisa diubah") {
+			t.Errorf("PATCH %s = %d %s, want 400 field tidak bisa diubah", body, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestKosongkanFieldServer(t *testing.T) {
+	tempat := model.Tempat{
+		Nama_Tempat: "Monas",
+		Status:      "tutup",
+		Versi:       3,
+		Ditandai:    true,
+		Laporan:     &model.RingkasanLaporan{},
+		Galeri:      []model.Foto{{URL: "a"}},
+		DeletedBy:   "admin",
+	}
+	kosongkanFieldServer(&tempat)
+	if want := (model.Tempat{Nama_Tempat: "Monas"}); !reflect.DeepEqual(tempat, want) {
+		t.Errorf("kosongkanFieldServer = %+v, want hanya field client", tempat)
+	}
+}
@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/middleware"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	AksiCreate  = "create"
+	AksiUpdate  = "update"
+	AksiDelete  = "delete"
+	AksiRestore = "restore"
+	AksiRevert  = "revert"
+	AksiMerge   = "merge"
+	AksiPurge   = "purge"
+)
+
+// GetRevisi menampilkan riwayat revisi satu tempat dari ?tempat_id=, terbaru lebih dulu
+func GetRevisi(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	tempatID, err := primitive.ObjectIDFromHex(req.URL.Query().Get("tempat_id"))
+	if err != nil {
+		resp.Response = "tempat_id tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "waktu", Value: -1}, {Key: "_id", Value: -1}})
+	revisi, err := atdb.GetAllDoc[[]model.Revisi](config.Mongoconn, "tempat_revisi", bson.M{"tempat_id": tempatID}, opts)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if revisi == nil {
+		revisi = []model.Revisi{}
+	}
+	helper.WriteJSON(respw, http.StatusOK, revisi)
+}
+
+// GetDiffRevisi membandingkan snapshot dua revisi ?dari= dan ?ke= milik tempat yang sama
+func GetDiffRevisi(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	query := req.URL.Query()
+	dari, err := getRevisi(query.Get("dari"))
+	if err != nil {
+		writeRevisiError(respw, err)
+		return
+	}
+	ke, err := getRevisi(query.Get("ke"))
+	if err != nil {
+		writeRevisiError(respw, err)
+		return
+	}
+	if dari.TempatID != ke.TempatID {
+		resp.Response = "Kedua revisi harus milik tempat yang sama"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, model.DiffRevisi{Dari: dari, Ke: ke, Diff: diffTempat(dari.Snapshot, ke.Snapshot)})
+}
+
+// PostRevertRevisi mengembalikan isi tempat ke snapshot revisi_id, revert sendiri dicatat sebagai revisi baru.
+// 412 jika tempat berubah di antara pembacaan dan penulisan.
+func PostRevertRevisi(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	var body struct {
+		RevisiID string `json:"revisi_id"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	revisi, err := getRevisi(body.RevisiID)
+	if err != nil {
+		writeRevisiError(respw, err)
+		return
+	}
+	if revisi.Snapshot == nil || revisi.Aksi == AksiPurge {
+		resp.Response = "Revisi ini tidak bisa dipakai untuk revert"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	sebelum, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", bson.M{"_id": revisi.TempatID})
+	if err != nil {
+		writeTempatError(respw, err)
+		return
+	}
+	//status trash, laporan, galeri dan gambar cover tetap milik dokumen saat ini, trash hanya diubah lewat endpoint trash
+	sesudah := *revisi.Snapshot
+	sesudah.DeletedAt, sesudah.DeletedBy = sebelum.DeletedAt, sebelum.DeletedBy
+	sesudah.Laporan, sesudah.Ditandai = sebelum.Laporan, sebelum.Ditandai
+	sesudah.Galeri, sesudah.Gambar = sebelum.Galeri, sebelum.Gambar
+	sesudah.Rating = sebelum.Rating
+	sesudah.Versi = sebelum.Versi + 1
+	result, err := atdb.ReplaceOneDoc(config.Mongoconn, "tempat", versiFilter(bson.M{"_id": revisi.TempatID}, sebelum.Versi), sesudah)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if result.MatchedCount == 0 {
+		current, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", bson.M{"_id": revisi.TempatID})
+		if err != nil {
+			writeTempatError(respw, err)
+			return
+		}
+		writeKonflikVersi(respw, current)
+		return
+	}
+	//rating di snapshot bisa sudah basi, hitung ulang dari ulasan saat ini
+	if err := perbaruiRating(sesudah.ID); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	catatan := revisiTempat(req, AksiRevert, &sebelum, &sesudah)
+	catatan.Dari = &revisi.ID
+	if _, err := atdb.InsertOneDoc(config.Mongoconn, "tempat_revisi", catatan); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, sesudah)
+}
+
+func getRevisi(hex string) (model.Revisi, error) {
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return model.Revisi{}, primitive.ErrInvalidHex
+	}
+	return atdb.GetOneDoc[model.Revisi](config.Mongoconn, "tempat_revisi", bson.M{"_id": id})
+}
+
+func writeRevisiError(respw http.ResponseWriter, err error) {
+	var resp itmodel.Response
+	switch err {
+	case mongo.ErrNoDocuments:
+		resp.Response = "Revisi tidak ditemukan"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+	case primitive.ErrInvalidHex:
+		resp.Response = "ID revisi tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+	default:
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+	}
+}
+
+// catatRevisi menyimpan satu revisi, sebelum nil untuk create. Update yang tidak mengubah apapun tidak dicatat.
+func catatRevisi(req *http.Request, aksi string, sebelum, sesudah *model.Tempat) error {
+	revisi := revisiTempat(req, aksi, sebelum, sesudah)
+	if aksi == AksiUpdate && len(revisi.Diff) == 0 {
+		return nil
+	}
+	_, err := atdb.InsertOneDoc(config.Mongoconn, "tempat_revisi", revisi)
+	return err
+}
+
+func revisiTempat(req *http.Request, aksi string, sebelum, sesudah *model.Tempat) model.Revisi {
+	revisi := model.Revisi{
+		Aksi:    aksi,
+		AdminID: middleware.GetAdminID(req),
+		Waktu:   time.Now().UTC(),
+	}
+	if aksi == AksiPurge {
+		revisi.TempatID = sebelum.ID
+		revisi.Snapshot = sebelum
+		revisi.Diff = []model.Perubahan{}
+		return revisi
+	}
+	revisi.TempatID = sesudah.ID
+	revisi.Snapshot = sesudah
+	revisi.Diff = diffTempat(sebelum, sesudah)
+	return revisi
+}
+
+// diffTempat membandingkan field bson tingkat atas kecuali _id dan versi, field yang tidak ada di salah satu sisi bernilai nil
+func diffTempat(lama, baru *model.Tempat) []model.Perubahan {
+	a, b := tempatKeMap(lama), tempatKeMap(baru)
+	fields := map[string]bool{}
+	for k := range a {
+		fields[k] = true
+	}
+	for k := range b {
+		fields[k] = true
+	}
+	diff := []model.Perubahan{}
+	for field := range fields {
+		if field == "_id" || field == "versi" || reflect.DeepEqual(a[field], b[field]) {
+			continue
+		}
+		diff = append(diff, model.Perubahan{Field: field, Lama: a[field], Baru: b[field]})
+	}
+	sort.Slice(diff, func(i, j int) bool { return diff[i].Field < diff[j].Field })
+	return diff
+}
+
+func tempatKeMap(t *model.Tempat) bson.M {
+	m := bson.M{}
+	if t == nil {
+		return m
+	}
+	if raw, err := bson.Marshal(t); err == nil {
+		bson.Unmarshal(raw, &m)
+	}
+	return m
+}
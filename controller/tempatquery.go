@@ -0,0 +1,285 @@
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/geo"
+	"github.com/gocroot/helper/jadwal"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// tempatQuery adalah hasil parsing parameter query daftar tempat
+type tempatQuery struct {
+	legacy     bool //tanpa parameter apapun, respon tetap array polos seperti semula
+	conds      []bson.M
+	sort       string
+	limit      int64
+	after      *tempatCursor
+	projection bson.M
+	buka       waktuBuka
+}
+
+// waktuBuka adalah acuan status buka dari ?open_at= atau ?open_now=true, saring true jika tempat yang tutup dibuang
+type waktuBuka struct {
+	at     time.Time
+	saring bool
+}
+
+// tempatCursor menyimpan posisi dokumen terakhir pada halaman sebelumnya
+type tempatCursor struct {
+	Nama string `json:"n,omitempty"`
+	ID   string `json:"id"`
+}
+
+// sort yang didukung, created memakai _id karena ObjectID memuat waktu pembuatan
+var tempatSort = map[string]string{
+	"nama":     "nama_tempat",
+	"-nama":    "nama_tempat",
+	"created":  "_id",
+	"-created": "_id",
+}
+
+// tempatFields adalah nama field bson model.Tempat yang boleh dipakai di ?fields=
+var tempatFields = bsonFieldNames(reflect.TypeOf(model.Tempat{}))
+
+func parseTempatQuery(req *http.Request) (q tempatQuery, err error) {
+	query := req.URL.Query()
+	q.legacy = len(query) == 0
+	q.sort = "created"
+	q.limit = config.LokasiMaxResult
+
+	q.conds, err = tempatFilters(query)
+	if err != nil {
+		return
+	}
+	q.buka, err = parseWaktuBuka(query)
+	if err != nil {
+		return
+	}
+	if sort := query.Get("sort"); sort != "" {
+		if _, ok := tempatSort[sort]; !ok {
+			err = errors.New("sort harus salah satu dari nama, -nama, created, -created")
+			return
+		}
+		q.sort = sort
+	}
+	if limit := query.Get("limit"); limit != "" {
+		q.limit, err = strconv.ParseInt(limit, 10, 64)
+		if err != nil || q.limit <= 0 {
+			err = errors.New("limit harus bilangan bulat positif")
+			return
+		}
+		q.limit = min(q.limit, config.LokasiMaxResult)
+	}
+	if after := query.Get("after"); after != "" {
+		q.after, err = decodeTempatCursor(after)
+		if err != nil {
+			return
+		}
+	}
+	if fields := query.Get("fields"); fields != "" {
+		q.projection = bson.M{"_id": 1}
+		for _, field := range strings.Split(fields, ",") {
+			field = strings.TrimSpace(field)
+			if !tempatFields[field] {
+				err = errors.New("field tidak dikenal: " + field)
+				return
+			}
+			q.projection[field] = 1
+		}
+		//field sort dibutuhkan untuk menyusun next_cursor
+		q.projection[tempatSort[q.sort]] = 1
+		q.projection["jam_buka"] = 1
+	}
+	return
+}
+
+// tempatFilters menyusun filter yang berlaku untuk semua endpoint daftar tempat
+func tempatFilters(query url.Values) (conds []bson.M, err error) {
+	conds = append(conds, tempatAktif(bson.M{}))
+	if bbox := query.Get("bbox"); bbox != "" {
+		var box geo.BBox
+		box, err = geo.ParseBBox(bbox)
+		if err != nil {
+			return
+		}
+		conds = append(conds, bboxFilter(box))
+	}
+	if fasilitas := query.Get("fasilitas"); fasilitas != "" {
+		tags := bson.A{}
+		for _, tag := range strings.Split(fasilitas, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		conds = append(conds, bson.M{"fasilitas_tags": bson.M{"$all": tags}})
+	}
+	if kendaraan := query.Get("kendaraan"); kendaraan != "" {
+		var kendaraanConds []bson.M
+		kendaraanConds, err = kendaraanFilter(kendaraan)
+		if err != nil {
+			return
+		}
+		conds = append(conds, kendaraanConds...)
+	}
+	if wilayah := query.Get("wilayah"); wilayah != "" {
+		var cond bson.M
+		cond, err = wilayahFilter(wilayah, query.Get("kategori_wilayah"))
+		if err != nil {
+			return
+		}
+		conds = append(conds, cond)
+	}
+	return
+}
+
+func parseWaktuBuka(query url.Values) (w waktuBuka, err error) {
+	w.at = time.Now()
+	if openAt := query.Get("open_at"); openAt != "" {
+		w.at, err = jadwal.ParseWaktu(openAt)
+		w.saring = true
+	} else if query.Get("open_now") == "true" {
+		w.saring = true
+	}
+	return
+}
+
+// terapkan mengisi StatusBuka, tempat dengan status unknown tetap ditampilkan saat disaring
+func (w waktuBuka) terapkan(t *model.Tempat) bool {
+	t.StatusBuka = jadwal.Status(t.JamBuka, w.at, config.SegeraTutup)
+	return !w.saring || t.StatusBuka != jadwal.StatusTutup
+}
+
+// saringTempat membuang elemen yang keep-nya false, slice asal ikut berubah
+func saringTempat[T any](list []T, keep func(*T) bool) []T {
+	hasil := list[:0]
+	for i := range list {
+		if keep(&list[i]) {
+			hasil = append(hasil, list[i])
+		}
+	}
+	return hasil
+}
+
+// tempatAktif menambahkan syarat tempat belum masuk trash (soft delete) ke filter
+func tempatAktif(filter bson.M) bson.M {
+	filter["deleted_at"] = bson.M{"$exists": false}
+	return filter
+}
+
+// andFilter menggabungkan beberapa kondisi filter dengan $and
+func andFilter(conds ...bson.M) bson.M {
+	if len(conds) == 0 {
+		return bson.M{}
+	}
+	if len(conds) == 1 {
+		return conds[0]
+	}
+	and := bson.A{}
+	for _, cond := range conds {
+		and = append(and, cond)
+	}
+	return bson.M{"$and": and}
+}
+
+func (q tempatQuery) filter() bson.M {
+	conds := q.conds
+	if q.after != nil {
+		conds = append(conds, q.cursorFilter())
+	}
+	return andFilter(conds...)
+}
+
+func (q tempatQuery) desc() bool {
+	return strings.HasPrefix(q.sort, "-")
+}
+
+func (q tempatQuery) cursorFilter() bson.M {
+	op := "$gt"
+	if q.desc() {
+		op = "$lt"
+	}
+	id, _ := primitive.ObjectIDFromHex(q.after.ID)
+	if tempatSort[q.sort] == "_id" {
+		return bson.M{"_id": bson.M{op: id}}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{"nama_tempat": bson.M{op: q.after.Nama}},
+		bson.M{"nama_tempat": q.after.Nama, "_id": bson.M{op: id}},
+	}}
+}
+
+// findOptions mengurutkan dokumen untuk dibaca lewat find, limit dihitung setelah saringan jam buka di cari
+func (q tempatQuery) findOptions() *options.FindOptions {
+	dir := 1
+	if q.desc() {
+		dir = -1
+	}
+	sort := bson.D{{Key: "_id", Value: dir}}
+	if field := tempatSort[q.sort]; field != "_id" {
+		sort = bson.D{{Key: field, Value: dir}, {Key: "_id", Value: dir}}
+	}
+	opts := options.Find().SetSort(sort)
+	if !q.buka.saring {
+		opts.SetLimit(q.limit + 1)
+	}
+	if q.projection != nil {
+		opts.SetProjection(q.projection)
+	}
+	return opts
+}
+
+// cari mengambil satu dokumen lolos saringan lebih dari limit untuk mengetahui apakah masih ada halaman berikutnya
+func (q tempatQuery) cari() (list model.TempatList, err error) {
+	list.Data, err = atdb.GetFilteredDoc(config.Mongoconn, "tempat", q.filter(), q.limit+1, q.buka.terapkan, q.findOptions())
+	if err != nil {
+		return
+	}
+	if int64(len(list.Data)) > q.limit {
+		list.Data = list.Data[:q.limit]
+		list.Truncated = true
+		last := list.Data[len(list.Data)-1]
+		list.NextCursor = encodeTempatCursor(tempatCursor{Nama: last.Nama_Tempat, ID: last.ID.Hex()})
+	}
+	return
+}
+
+func encodeTempatCursor(c tempatCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeTempatCursor(s string) (c *tempatCursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil || c == nil || !primitive.IsValidObjectID(c.ID) {
+		return nil, errors.New("cursor after tidak valid")
+	}
+	return
+}
+
+func bsonFieldNames(t reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("bson"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
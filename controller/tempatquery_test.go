@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestDecodeTempatCursor(t *testing.T) {
+	valid := tempatCursor{Nama: "Parkir Gratis Monas", ID: "65a1b2c3d4e5f60718293a4b"}
+	got, err := decodeTempatCursor(encodeTempatCursor(valid))
+	if err != nil {
+		t.Fatalf("round trip error = %v", err)
+	}
+	if *got != valid {
+		t.Fatalf("round trip = %+v, want %+v", *got, valid)
+	}
+
+	invalid := map[string]string{
+		"bukan base64": "!!!",
+		"bukan json":   base64.RawURLEncoding.EncodeToString([]byte("bukan json")),
+		"null":         base64.RawURLEncoding.EncodeToString([]byte("null")),
+		"tanpa id":     base64.RawURLEncoding.EncodeToString([]byte(`{"n":"a"}`)),
+		"id bukan hex": base64.RawURLEncoding.EncodeToString([]byte(`{"id":"zzzzzzzzzzzzzzzzzzzzzzzz"}`)),
+	}
+	for name, s := range invalid {
+		if c, err := decodeTempatCursor(s); err == nil {
+			t.Errorf("%s: decodeTempatCursor(%q) = %+v, want error", name, s, c)
+		}
+	}
+}
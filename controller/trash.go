@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetTrashTempat menampilkan tempat yang sudah dihapus, terbaru lebih dulu
+func GetTrashTempat(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	opts := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}})
+	tempat, err := atdb.GetAllDoc[[]model.Tempat](config.Mongoconn, "tempat", bson.M{"deleted_at": bson.M{"$exists": true}}, opts)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if tempat == nil {
+		tempat = []model.Tempat{}
+	}
+	helper.WriteJSON(respw, http.StatusOK, tempat)
+}
+
+// PostRestoreTempat mengeluarkan tempat dari trash
+func PostRestoreTempat(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(body.ID)
+	if err != nil {
+		resp.Response = "ID tempat tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	filter := bson.M{"_id": id, "deleted_at": bson.M{"$exists": true}}
+	sebelum, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", filter)
+	if err == mongo.ErrNoDocuments {
+		resp.Response = "Tempat tidak ditemukan di trash"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if _, err := atdb.UpdateDoc(config.Mongoconn, "tempat", filter, bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}, "$inc": bson.M{"versi": 1}}); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	tempat := sebelum
+	tempat.DeletedAt = nil
+	tempat.DeletedBy = ""
+	tempat.Versi++
+	if err := catatRevisi(req, AksiRestore, &sebelum, &tempat); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, tempat)
+}
+
+// DeletePurgeTempat menghapus permanen tempat yang sudah di trash lebih dari ?hari= (default config.TrashRetensiHari).
+// Marker yang menunjuk ke tempat tersebut dilepas tautannya, bukan ikut dihapus.
+func DeletePurgeTempat(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	hari := config.TrashRetensiHari
+	if h := req.URL.Query().Get("hari"); h != "" {
+		n, err := strconv.Atoi(h)
+		if err != nil || n < 0 {
+			resp.Response = "Parameter hari harus bilangan bulat tidak negatif"
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		hari = n
+	}
+	batas := time.Now().UTC().AddDate(0, 0, -hari)
+	filter := bson.M{"deleted_at": bson.M{"$lte": batas}}
+
+	tempat, err := atdb.GetAllDoc[[]model.Tempat](config.Mongoconn, "tempat", filter)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if len(tempat) == 0 {
+		resp.Response = "Tidak ada tempat yang perlu dihapus"
+		resp.Info = "0"
+		helper.WriteJSON(respw, http.StatusOK, resp)
+		return
+	}
+	ids := bson.A{}
+	var revisi []interface{}
+	for i := range tempat {
+		ids = append(ids, tempat[i].ID)
+		revisi = append(revisi, revisiTempat(req, AksiPurge, &tempat[i], nil))
+	}
+	deleted, err := atdb.DeleteManyDocs(config.Mongoconn, "tempat", bson.M{"_id": bson.M{"$in": ids}, "deleted_at": bson.M{"$lte": batas}})
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	update := bson.M{"$unset": bson.M{"tempat_id": ""}, "$set": bson.M{"updated_at": time.Now().UTC()}}
+	if _, err := atdb.UpdateManyDoc(config.Mongoconn, "penanda", bson.M{"tempat_id": bson.M{"$in": ids}}, update); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	//snapshot terakhir disimpan di revisi supaya data yang di-purge masih bisa ditelusuri
+	if _, err := atdb.InsertManyDocs(config.Mongoconn, "tempat_revisi", revisi); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	resp.Response = "Tempat dihapus permanen"
+	resp.Info = strconv.FormatInt(deleted, 10)
+	helper.WriteJSON(respw, http.StatusOK, resp)
+}
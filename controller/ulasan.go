@@ -0,0 +1,259 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/validasi"
+	"github.com/gocroot/middleware"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ulasanRequest adalah payload ulasan publik, reviewer dikenali dari phone atau session
+type ulasanRequest struct {
+	TempatID string `json:"tempat_id"`
+	Rating   int    `json:"rating"`
+	Komentar string `json:"komentar"`
+	Foto     string `json:"foto"`
+	Phone    string `json:"phone"`
+	Session  string `json:"session"`
+}
+
+// GetUlasan menampilkan ulasan yang tidak disembunyikan untuk ?tempat_id=, terbaru lebih dulu
+func GetUlasan(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	tempatID, err := primitive.ObjectIDFromHex(req.URL.Query().Get("tempat_id"))
+	if err != nil {
+		resp.Response = "tempat_id tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	daftarUlasan(respw, bson.M{"tempat_id": tempatID, "hidden": bson.M{"$ne": true}})
+}
+
+// GetUlasanAdmin menampilkan semua ulasan termasuk yang disembunyikan, bisa disaring ?tempat_id= dan ?hidden=true
+func GetUlasanAdmin(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	query := req.URL.Query()
+	filter := bson.M{}
+	if query.Get("tempat_id") != "" {
+		tempatID, err := primitive.ObjectIDFromHex(query.Get("tempat_id"))
+		if err != nil {
+			resp.Response = "tempat_id tidak valid"
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		filter["tempat_id"] = tempatID
+	}
+	if query.Get("hidden") == "true" {
+		filter["hidden"] = true
+	}
+	daftarUlasan(respw, filter)
+}
+
+func daftarUlasan(respw http.ResponseWriter, filter bson.M) {
+	var resp itmodel.Response
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	ulasan, err := atdb.GetAllDoc[[]model.Ulasan](config.Mongoconn, "ulasan", filter, opts)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if ulasan == nil {
+		ulasan = []model.Ulasan{}
+	}
+	helper.WriteJSON(respw, http.StatusOK, ulasan)
+}
+
+// PostUlasan menyimpan ulasan baru lalu menghitung ulang rating tempat
+func PostUlasan(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	var body ulasanRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	ulasan := model.Ulasan{
+		Rating:    body.Rating,
+		Komentar:  strings.TrimSpace(body.Komentar),
+		Foto:      body.Foto,
+		CreatedAt: time.Now().UTC(),
+	}
+	errs := validasi.Ulasan(&ulasan)
+	reviewer, ok := identitasPengguna(body.Phone, body.Session)
+	if !ok {
+		errs.Add("phone", "isi nomor telepon yang valid atau session")
+	}
+	tempatID, err := primitive.ObjectIDFromHex(body.TempatID)
+	if err != nil {
+		errs.Add("tempat_id", "tidak valid")
+	}
+	if len(errs) > 0 {
+		writeValidasi(respw, errs)
+		return
+	}
+	if _, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", tempatAktif(bson.M{"_id": tempatID})); err != nil {
+		writeTempatError(respw, err)
+		return
+	}
+	ulasan.TempatID = tempatID
+	ulasan.Reviewer = reviewer
+	if ulasan.Foto != "" {
+		ulasan.Foto = config.GambarBaseURL + strings.TrimPrefix(ulasan.Foto, config.GambarBaseURL)
+	}
+
+	id, err := atdb.InsertOneDoc(config.Mongoconn, "ulasan", ulasan)
+	if mongo.IsDuplicateKeyError(err) {
+		resp.Response = "Anda sudah memberi ulasan untuk tempat ini"
+		helper.WriteJSON(respw, http.StatusConflict, resp)
+		return
+	}
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	ulasan.ID = id.(primitive.ObjectID)
+	if err := perbaruiRating(tempatID); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusCreated, ulasan)
+}
+
+// PostHideUlasan menyembunyikan ulasan yang melanggar, ulasan tidak dihapus supaya reviewer yang sama tetap tidak bisa mengulas lagi
+func PostHideUlasan(respw http.ResponseWriter, req *http.Request) {
+	var body struct {
+		ID     string `json:"id"`
+		Alasan string `json:"alasan"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		helper.WriteJSON(respw, http.StatusBadRequest, itmodel.Response{Response: err.Error()})
+		return
+	}
+	body.Alasan = strings.TrimSpace(body.Alasan)
+	if body.Alasan == "" {
+		writeValidasi(respw, []model.FieldError{{Field: "alasan", Pesan: "wajib diisi"}})
+		return
+	}
+	ubahHiddenUlasan(respw, body.ID, bson.M{"$set": bson.M{
+		"hidden":        true,
+		"hidden_alasan": body.Alasan,
+		"hidden_by":     middleware.GetAdminID(req),
+	}})
+}
+
+// PostUnhideUlasan menampilkan kembali ulasan yang disembunyikan
+func PostUnhideUlasan(respw http.ResponseWriter, req *http.Request) {
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		helper.WriteJSON(respw, http.StatusBadRequest, itmodel.Response{Response: err.Error()})
+		return
+	}
+	ubahHiddenUlasan(respw, body.ID, bson.M{"$unset": bson.M{"hidden": "", "hidden_alasan": "", "hidden_by": ""}})
+}
+
+func ubahHiddenUlasan(respw http.ResponseWriter, idHex string, update bson.M) {
+	var resp itmodel.Response
+	id, err := primitive.ObjectIDFromHex(idHex)
+	if err != nil {
+		resp.Response = "ID ulasan tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var ulasan model.Ulasan
+	err = config.Mongoconn.Collection("ulasan").FindOneAndUpdate(context.Background(), bson.M{"_id": id}, update, opts).Decode(&ulasan)
+	if err == mongo.ErrNoDocuments {
+		resp.Response = "Ulasan tidak ditemukan"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if err := perbaruiRating(ulasan.TempatID); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, ulasan)
+}
+
+// perbaruiRating menghitung ulang rata-rata dan jumlah ulasan yang tampil lalu menyimpannya di tempat
+func perbaruiRating(tempatID primitive.ObjectID) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"tempat_id": tempatID, "hidden": bson.M{"$ne": true}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       nil,
+			"rata_rata": bson.M{"$avg": "$rating"},
+			"jumlah":    bson.M{"$sum": 1},
+		}}},
+	}
+	hasil, err := atdb.GetAggregateDoc[[]model.Rating](config.Mongoconn, "ulasan", pipeline)
+	if err != nil {
+		return err
+	}
+	update := bson.M{"$unset": bson.M{"rating": ""}}
+	if len(hasil) > 0 && hasil[0].Jumlah > 0 {
+		rating := hasil[0]
+		rating.RataRata = math.Round(rating.RataRata*100) / 100
+		update = bson.M{"$set": bson.M{"rating": rating}}
+	}
+	_, err = atdb.UpdateDoc(config.Mongoconn, "tempat", bson.M{"_id": tempatID}, update)
+	return err
+}
+
+// identitasPengguna menyusun identitas reviewer atau pelapor dari nomor telepon (diutamakan) atau session, disimpan sebagai hash
+func identitasPengguna(phone, session string) (string, bool) {
+	var key string
+	if phone = normalisasiPhone(phone); phone != "" {
+		key = "phone:" + phone
+	} else if session = strings.TrimSpace(session); len(session) >= 16 {
+		key = "session:" + session
+	} else {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]), true
+}
+
+// normalisasiPhone mengubah 08xx dan +628xx menjadi 628xx, string kosong jika bukan nomor yang masuk akal
+func normalisasiPhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		}
+	}
+	nomor := digits.String()
+	if strings.HasPrefix(nomor, "0") {
+		nomor = "62" + nomor[1:]
+	}
+	if len(nomor) < 10 || len(nomor) > 15 {
+		return ""
+	}
+	return nomor
+}
@@ -0,0 +1,41 @@
+package controller
+
+import "testing"
+
+func TestNormalisasiPhone(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{"081234567890", "6281234567890"},
+		{"+62 812-3456-7890", "6281234567890"},
+		{"62 812 3456 7890", "6281234567890"},
+		{"(0812) 3456 7890", "6281234567890"},
+		{"0812345", ""},
+		{"6281234567890123456", ""},
+		{"bukan nomor", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalisasiPhone(tt.input); got != tt.want {
+			t.Errorf("normalisasiPhone(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestIdentitasPengguna(t *testing.T) {
+	a, ok := identitasPengguna("081234567890", "")
+	if !ok || len(a) != 64 {
+		t.Fatalf("identitas phone = %q, %v", a, ok)
+	}
+	if b, _ := identitasPengguna("+62 812-3456-7890", "session-lain-yang-panjang"); b != a {
+		t.Error("format nomor berbeda harus menghasilkan identitas yang sama dan phone diutamakan dari session")
+	}
+	s, ok := identitasPengguna("", "0123456789abcdef")
+	if !ok || s == a {
+		t.Errorf("identitas session = %q, %v", s, ok)
+	}
+	if _, ok := identitasPengguna("", "pendek"); ok {
+		t.Error("session kurang dari 16 karakter harus ditolak")
+	}
+	if _, ok := identitasPengguna("123", ""); ok {
+		t.Error("nomor tidak valid tanpa session harus ditolak")
+	}
+}
@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/middleware"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	UsulanPending  = "pending"
+	UsulanApproved = "approved"
+	UsulanRejected = "rejected"
+)
+
+// GetStatusUsulan dipakai pengirim untuk melihat status usulan dari kode lacak
+func GetStatusUsulan(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	kode := strings.ToUpper(helper.GetParam(req))
+	usulan, err := atdb.GetOneDoc[model.Usulan](config.Mongoconn, "tempat_usulan", bson.M{"kode": kode})
+	if err == mongo.ErrNoDocuments {
+		resp.Response = "Kode usulan tidak ditemukan"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, statusUsulan(usulan))
+}
+
+// GetUsulan menampilkan antrian usulan dengan ?status= (default pending), yang paling lama menunggu lebih dulu
+func GetUsulan(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	status := req.URL.Query().Get("status")
+	if status == "" {
+		status = UsulanPending
+	}
+	if status != UsulanPending && status != UsulanApproved && status != UsulanRejected {
+		resp.Response = "status harus salah satu dari pending, approved, rejected"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "diajukan_at", Value: 1}})
+	usulan, err := atdb.GetAllDoc[[]model.Usulan](config.Mongoconn, "tempat_usulan", bson.M{"status": status}, opts)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if usulan == nil {
+		usulan = []model.Usulan{}
+	}
+	helper.WriteJSON(respw, http.StatusOK, usulan)
+}
+
+// PostApproveUsulan memindahkan usulan pending ke koleksi tempat.
+// Jika data dikirim, moderator menyunting isi usulan dulu dan data itulah yang disimpan.
+func PostApproveUsulan(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	var body struct {
+		ID   string        `json:"id"`
+		Data *model.Tempat `json:"data"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	usulan, ok := usulanPending(respw, body.ID)
+	if !ok {
+		return
+	}
+	data := usulan.Data
+	if body.Data != nil {
+		data = *body.Data
+	}
+	errs, err := validasiTempat(&data)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if len(errs) > 0 {
+		writeValidasi(respw, errs)
+		return
+	}
+
+	//usulan diklaim dulu supaya dua moderator tidak menyetujui usulan yang sama dua kali
+	now := time.Now().UTC()
+	klaim := bson.M{"$set": bson.M{"status": UsulanApproved, "diputuskan_at": now, "moderator_id": middleware.GetAdminID(req)}}
+	result, err := atdb.UpdateDoc(config.Mongoconn, "tempat_usulan", bson.M{"_id": usulan.ID, "status": UsulanPending}, klaim)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if result.MatchedCount == 0 {
+		resp.Response = "Usulan sudah diputuskan moderator lain"
+		helper.WriteJSON(respw, http.StatusConflict, resp)
+		return
+	}
+	tempatID, errSimpan := simpanTempat(req, &data)
+	if tempatID.IsZero() {
+		//klaim hanya dibatalkan jika tempat belum tersimpan, selain itu approve ulang akan membuat tempat ganda
+		batal := bson.M{"$set": bson.M{"status": UsulanPending}, "$unset": bson.M{"diputuskan_at": "", "moderator_id": ""}}
+		atdb.UpdateDoc(config.Mongoconn, "tempat_usulan", bson.M{"_id": usulan.ID}, batal)
+		resp.Response = errSimpan.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if _, err := atdb.UpdateDoc(config.Mongoconn, "tempat_usulan", bson.M{"_id": usulan.ID}, bson.M{"$set": bson.M{"tempat_id": tempatID, "data": data}}); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if errSimpan != nil {
+		resp.Response = "Tempat tersimpan dengan ID " + tempatID.Hex() + " tetapi revisi gagal dicatat: " + errSimpan.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	helper.WriteJSON(respw, http.StatusOK, data)
+}
+
+// PostRejectUsulan menolak usulan pending, alasan wajib diisi karena ditampilkan ke pengirim
+func PostRejectUsulan(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	var body struct {
+		ID     string `json:"id"`
+		Alasan string `json:"alasan"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	body.Alasan = strings.TrimSpace(body.Alasan)
+	if body.Alasan == "" {
+		writeValidasi(respw, []model.FieldError{{Field: "alasan", Pesan: "wajib diisi"}})
+		return
+	}
+	usulan, ok := usulanPending(respw, body.ID)
+	if !ok {
+		return
+	}
+	update := bson.M{"$set": bson.M{
+		"status":        UsulanRejected,
+		"alasan":        body.Alasan,
+		"diputuskan_at": time.Now().UTC(),
+		"moderator_id":  middleware.GetAdminID(req),
+	}}
+	result, err := atdb.UpdateDoc(config.Mongoconn, "tempat_usulan", bson.M{"_id": usulan.ID, "status": UsulanPending}, update)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if result.MatchedCount == 0 {
+		resp.Response = "Usulan sudah diputuskan moderator lain"
+		helper.WriteJSON(respw, http.StatusConflict, resp)
+		return
+	}
+	resp.Response = "Usulan ditolak"
+	resp.Info = usulan.Kode
+	helper.WriteJSON(respw, http.StatusOK, resp)
+}
+
+// usulanPending mengambil usulan dan menulis respon error jika tidak ada atau sudah diputuskan
+func usulanPending(respw http.ResponseWriter, idHex string) (usulan model.Usulan, ok bool) {
+	var resp itmodel.Response
+	id, err := primitive.ObjectIDFromHex(idHex)
+	if err != nil {
+		resp.Response = "ID usulan tidak valid"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	usulan, err = atdb.GetOneDoc[model.Usulan](config.Mongoconn, "tempat_usulan", bson.M{"_id": id})
+	if err == mongo.ErrNoDocuments {
+		resp.Response = "Usulan tidak ditemukan"
+		helper.WriteJSON(respw, http.StatusNotFound, resp)
+		return
+	}
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	if usulan.Status != UsulanPending {
+		resp.Response = "Usulan sudah " + usulan.Status
+		helper.WriteJSON(respw, http.StatusConflict, resp)
+		return
+	}
+	return usulan, true
+}
+
+// ajukanUsulan menyimpan tempat yang sudah lolos validasi ke antrian moderasi dengan kode lacak acak
+func ajukanUsulan(data model.Tempat) (usulan model.Usulan, err error) {
+	kosongkanFieldServer(&data)
+	data.ID = primitive.NilObjectID
+	usulan = model.Usulan{
+		Status:     UsulanPending,
+		Data:       data,
+		DiajukanAt: time.Now().UTC(),
+	}
+	//kode unik dijaga index, bentrok diulang beberapa kali saja karena peluangnya sangat kecil
+	for i := 0; i < 3; i++ {
+		if usulan.Kode, err = kodeUsulan(); err != nil {
+			return
+		}
+		var id interface{}
+		id, err = atdb.InsertOneDoc(config.Mongoconn, "tempat_usulan", usulan)
+		if err == nil {
+			usulan.ID = id.(primitive.ObjectID)
+			return
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return
+		}
+	}
+	return
+}
+
+func kodeUsulan() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+func statusUsulan(u model.Usulan) model.StatusUsulan {
+	return model.StatusUsulan{
+		Kode:         u.Kode,
+		Status:       u.Status,
+		NamaTempat:   u.Data.Nama_Tempat,
+		Alasan:       u.Alasan,
+		TempatID:     u.TempatID,
+		DiajukanAt:   u.DiajukanAt,
+		DiputuskanAt: u.DiputuskanAt,
+	}
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/validasi"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// writeValidasi mengirim 422 berisi semua kesalahan field
+func writeValidasi(respw http.ResponseWriter, errs validasi.Errors) {
+	helper.WriteJSON(respw, http.StatusUnprocessableEntity, model.ValidasiGagal{Response: "Data tidak valid", Errors: errs})
+}
+
+// validasiTempat memeriksa isi tempat dan menormalkan fasilitas_tags terhadap kosakata di database.
+// err hanya terisi jika database gagal dibaca.
+func validasiTempat(t *model.Tempat) (validasi.Errors, error) {
+	errs := validasi.Tempat(t)
+	tags, unknown, err := normalizeFasilitasTags(t.FasilitasTags)
+	if err != nil {
+		return errs, err
+	}
+	if len(unknown) > 0 {
+		errs.Add("fasilitas_tags", "tidak dikenal: "+strings.Join(unknown, ", "))
+	} else {
+		t.FasilitasTags = tags
+	}
+	return errs, nil
+}
+
+// kosongkanFieldServer membuang field yang hanya boleh diisi server dari payload client
+func kosongkanFieldServer(t *model.Tempat) {
+	t.Location = nil
+	t.Wilayah = nil
+	t.DeletedAt = nil
+	t.DeletedBy = ""
+	t.Versi = 0
+	t.Rating = nil
+	t.Laporan = nil
+	t.Ditandai = false
+	t.Galeri = nil
+	//status tutup/berbayar/parkir_liar hanya diubah lewat triase laporan di PostTerapkanLaporan
+	t.Status = ""
+}
+
+// timpaTempat menghasilkan isi tempat setelah $set dengan struct baru, field kosong di baru tidak menimpa lama.
+// Field slice, map dan pointer dikosongkan dulu supaya diganti utuh dan tidak menulis ke memori milik lama.
+func timpaTempat(lama, baru model.Tempat) model.Tempat {
+	hasil := lama
+	if baru.FasilitasTags != nil {
+		hasil.FasilitasTags = nil
+	}
+	if baru.FasilitasReview != nil {
+		hasil.FasilitasReview = nil
+	}
+	if baru.JamBuka != nil {
+		hasil.JamBuka = nil
+	}
+	if baru.Kendaraan != nil {
+		hasil.Kendaraan = nil
+	}
+	if baru.Location != nil {
+		hasil.Location = nil
+	}
+	if raw, err := bson.Marshal(baru); err == nil {
+		bson.Unmarshal(raw, &hasil)
+	}
+	return hasil
+}
@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper"
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/geocode"
+	"github.com/gocroot/model"
+	"github.com/whatsauth/itmodel"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetWilayah menampilkan wilayah administrasi di koleksi lokasi beserta jumlah tempat di dalamnya, ?kategori= membatasi satu tingkat
+func GetWilayah(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	kategori := geocode.KategoriWilayah
+	if k := req.URL.Query().Get("kategori"); k != "" {
+		if !slices.Contains(geocode.KategoriWilayah, k) {
+			resp.Response = "Parameter kategori harus kelurahan, kecamatan atau kota"
+			helper.WriteJSON(respw, http.StatusBadRequest, resp)
+			return
+		}
+		kategori = []string{k}
+	}
+
+	opts := options.Find().SetProjection(bson.M{"batas": 0}).SetSort(bson.D{{Key: "kategori", Value: 1}, {Key: "nama", Value: 1}})
+	wilayah, err := atdb.GetAllDoc[[]model.WilayahStat](config.Mongoconn, "lokasi", bson.M{"kategori": bson.M{"$in": kategori}}, opts)
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	jumlah, err := jumlahTempatPerWilayah()
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	for i := range wilayah {
+		wilayah[i].Jumlah = jumlah[wilayah[i].Kategori][wilayah[i].Nama]
+	}
+	if wilayah == nil {
+		wilayah = []model.WilayahStat{}
+	}
+	helper.WriteJSON(respw, http.StatusOK, wilayah)
+}
+
+// jumlahTempatPerWilayah menghitung tempat per kategori lalu per nama wilayah dari field wilayah hasil reverse geocoding
+func jumlahTempatPerWilayah() (map[string]map[string]int, error) {
+	facet := bson.M{}
+	for _, k := range geocode.KategoriWilayah {
+		facet[k] = bson.A{
+			bson.M{"$match": bson.M{"wilayah." + k: bson.M{"$exists": true}}},
+			bson.M{"$group": bson.M{"_id": "$wilayah." + k, "jumlah": bson.M{"$sum": 1}}},
+		}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: tempatAktif(bson.M{})}},
+		{{Key: "$facet", Value: facet}},
+	}
+	hasil, err := atdb.GetAggregateDoc[[]map[string][]struct {
+		Nama   string `bson:"_id"`
+		Jumlah int    `bson:"jumlah"`
+	}](config.Mongoconn, "tempat", pipeline)
+	if err != nil {
+		return nil, err
+	}
+	jumlah := map[string]map[string]int{}
+	for _, k := range geocode.KategoriWilayah {
+		jumlah[k] = map[string]int{}
+		if len(hasil) == 0 {
+			continue
+		}
+		for _, g := range hasil[0][k] {
+			jumlah[k][g.Nama] = g.Jumlah
+		}
+	}
+	return jumlah, nil
+}
+
+// wilayahFilter mencocokkan nama wilayah di tingkat manapun, atau hanya di tingkat kategori jika diisi
+func wilayahFilter(nama, kategori string) (bson.M, error) {
+	if kategori != "" {
+		if !slices.Contains(geocode.KategoriWilayah, kategori) {
+			return nil, errors.New("kategori_wilayah harus kelurahan, kecamatan atau kota")
+		}
+		return bson.M{"wilayah." + kategori: nama}, nil
+	}
+	or := bson.A{}
+	for _, k := range geocode.KategoriWilayah {
+		or = append(or, bson.M{"wilayah." + k: nama})
+	}
+	return bson.M{"$or": or}, nil
+}
+
+// PostImportWilayah memuat poligon batas wilayah dari FeatureCollection GeoJSON di field "file".
+// ?kategori= wajib (kelurahan, kecamatan atau kota), ?nama= adalah nama properti yang berisi nama wilayah (default "nama").
+// Wilayah dengan nama dan kategori yang sama diganti.
+func PostImportWilayah(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	query := req.URL.Query()
+	kategori := query.Get("kategori")
+	if !slices.Contains(geocode.KategoriWilayah, kategori) {
+		resp.Response = "Parameter kategori harus kelurahan, kecamatan atau kota"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	properti := query.Get("nama")
+	if properti == "" {
+		properti = "nama"
+	}
+
+	req.Body = http.MaxBytesReader(respw, req.Body, config.WilayahImportMaxSize)
+	file, _, err := req.FormFile("file")
+	if err != nil {
+		resp.Response = "File GeoJSON tidak ditemukan di field file: " + err.Error()
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+	defer file.Close()
+
+	var fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry   model.Geometry         `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(file).Decode(&fc); err != nil || fc.Type != "FeatureCollection" {
+		resp.Response = "File harus berupa GeoJSON FeatureCollection"
+		helper.WriteJSON(respw, http.StatusBadRequest, resp)
+		return
+	}
+
+	report := model.ImportWilayah{Total: len(fc.Features)}
+	collection := config.Mongoconn.Collection("lokasi")
+	for i, feature := range fc.Features {
+		nama, _ := feature.Properties[properti].(string)
+		if nama == "" {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("feature %d: properti %s kosong", i, properti))
+			continue
+		}
+		if feature.Geometry.Type != "Polygon" && feature.Geometry.Type != "MultiPolygon" {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("feature %d (%s): geometry %s bukan Polygon/MultiPolygon", i, nama, feature.Geometry.Type))
+			continue
+		}
+		lokasi := model.Lokasi{Nama: nama, Kategori: kategori, Batas: feature.Geometry}
+		filter := bson.M{"nama": nama, "kategori": kategori}
+		if _, err := collection.ReplaceOne(req.Context(), filter, lokasi, options.Replace().SetUpsert(true)); err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("feature %d (%s): %s", i, nama, err.Error()))
+			continue
+		}
+		report.Disimpan++
+	}
+	helper.WriteJSON(respw, http.StatusOK, report)
+}
+
+// PostGeocodeTempat mengisi ulang wilayah semua tempat, dipakai setelah batas wilayah dimuat atau diperbarui
+func PostGeocodeTempat(respw http.ResponseWriter, req *http.Request) {
+	var resp itmodel.Response
+	tempat, err := atdb.GetAllDoc[[]model.Tempat](config.Mongoconn, "tempat", bson.M{"location": bson.M{"$exists": true}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		resp.Response = err.Error()
+		helper.WriteJSON(respw, http.StatusInternalServerError, resp)
+		return
+	}
+	var gagal int
+	for _, t := range tempat {
+		if err := perbaruiWilayah(t.ID); err != nil {
+			gagal++
+		}
+	}
+	resp.Response = fmt.Sprintf("%d tempat diproses, %d gagal", len(tempat), gagal)
+	helper.WriteJSON(respw, http.StatusOK, resp)
+}
+
+// isiWilayah mengisi Wilayah dari lon/lat, kegagalan hanya dicatat supaya penyimpanan tempat tetap berjalan
+func isiWilayah(tempat *model.Tempat) {
+	wilayah, err := geocode.ReverseGeocode(config.Mongoconn, tempat.Lon, tempat.Lat)
+	if err != nil {
+		log.Println("isiWilayah:", err)
+		return
+	}
+	tempat.Wilayah = wilayah
+}
+
+// perbaruiWilayah menghitung ulang wilayah dari lon/lat yang tersimpan
+func perbaruiWilayah(id primitive.ObjectID) error {
+	tempat, err := atdb.GetOneDoc[model.Tempat](config.Mongoconn, "tempat", bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	wilayah, err := geocode.ReverseGeocode(config.Mongoconn, tempat.Lon, tempat.Lat)
+	if err != nil {
+		log.Println("perbaruiWilayah:", err)
+		return err
+	}
+	if reflect.DeepEqual(wilayah, tempat.Wilayah) {
+		return nil
+	}
+	//versi ikut naik supaya ETag berubah, filter versi mencegah wilayah lama menimpa tempat yang baru dipindah
+	update := bson.M{"$unset": bson.M{"wilayah": ""}, "$inc": bson.M{"versi": 1}}
+	if wilayah != nil {
+		update = bson.M{"$set": bson.M{"wilayah": wilayah}, "$inc": bson.M{"versi": 1}}
+	}
+	_, err = atdb.UpdateDoc(config.Mongoconn, "tempat", versiFilter(bson.M{"_id": id}, tempat.Versi), update)
+	return err
+}
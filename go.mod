@@ -9,6 +9,7 @@ require (
 	github.com/google/go-github/v59 v59.0.0
 	github.com/whatsauth/itmodel v0.0.1
 	go.mongodb.org/mongo-driver v1.15.0
+	golang.org/x/image v0.18.0
 	golang.org/x/oauth2 v0.11.0
 )
 
@@ -35,7 +36,7 @@ require (
 	golang.org/x/net v0.21.0 // indirect
 	golang.org/x/sync v0.7.0 // indirect
 	golang.org/x/sys v0.20.0 // indirect
-	golang.org/x/text v0.15.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/protobuf v1.31.0 // indirect
 )
@@ -76,9 +76,20 @@ func GetRandomDoc[T any](db *mongo.Database, collection string, size uint) (resu
 	return
 }
 
-func GetAllDoc[T any](db *mongo.Database, collection string, filter bson.M) (doc T, err error) {
+func GetAggregateDoc[T any](db *mongo.Database, collection string, pipeline mongo.Pipeline) (doc T, err error) {
 	ctx := context.Background()
-	cur, err := db.Collection(collection).Find(ctx, filter)
+	cur, err := db.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return
+	}
+	defer cur.Close(ctx)
+	err = cur.All(ctx, &doc)
+	return
+}
+
+func GetAllDoc[T any](db *mongo.Database, collection string, filter bson.M, opts ...*options.FindOptions) (doc T, err error) {
+	ctx := context.Background()
+	cur, err := db.Collection(collection).Find(ctx, filter, opts...)
 	if err != nil {
 		return
 	}
@@ -90,6 +101,43 @@ func GetAllDoc[T any](db *mongo.Database, collection string, filter bson.M) (doc
 	return
 }
 
+// GetFilteredDoc seperti GetAllDoc tetapi hanya menyimpan dokumen yang lolos keep sampai limit dokumen.
+// Dipakai untuk saringan yang tidak bisa ditulis sebagai query, jadi opts sebaiknya tidak memasang limit sendiri.
+func GetFilteredDoc[T any](db *mongo.Database, collection string, filter bson.M, limit int64, keep func(*T) bool, opts ...*options.FindOptions) ([]T, error) {
+	ctx := context.Background()
+	cur, err := db.Collection(collection).Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return decodeFiltered(ctx, cur, limit, keep)
+}
+
+// GetFilteredAggregateDoc adalah GetFilteredDoc untuk aggregation pipeline
+func GetFilteredAggregateDoc[T any](db *mongo.Database, collection string, pipeline mongo.Pipeline, limit int64, keep func(*T) bool) ([]T, error) {
+	ctx := context.Background()
+	cur, err := db.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	return decodeFiltered(ctx, cur, limit, keep)
+}
+
+// decodeFiltered membaca cursor sampai limit dokumen lolos keep, dokumen yang dibuang tidak dihitung ke limit
+func decodeFiltered[T any](ctx context.Context, cur *mongo.Cursor, limit int64, keep func(*T) bool) ([]T, error) {
+	defer cur.Close(ctx)
+	hasil := []T{}
+	for int64(len(hasil)) < limit && cur.Next(ctx) {
+		var doc T
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		if keep(&doc) {
+			hasil = append(hasil, doc)
+		}
+	}
+	return hasil, cur.Err()
+}
+
 func GetOneDoc[T any](db *mongo.Database, collection string, filter bson.M) (doc T, err error) {
 	err = db.Collection(collection).FindOne(context.Background(), filter).Decode(&doc)
 	if err != nil {
@@ -115,6 +163,16 @@ func InsertOneDoc(db *mongo.Database, collection string, doc interface{}) (inser
 	return insertResult.InsertedID, nil
 }
 
+// insertedIDs tetap dikembalikan saat err berupa mongo.BulkWriteException, isinya ID semua dokumen yang dikirim
+// sehingga dokumen yang gagal harus dibuang berdasarkan WriteErrors[i].Index
+func InsertManyDocs(db *mongo.Database, collection string, docs []interface{}, opts ...*options.InsertManyOptions) (insertedIDs []interface{}, err error) {
+	insertResult, err := db.Collection(collection).InsertMany(context.TODO(), docs, opts...)
+	if insertResult != nil {
+		insertedIDs = insertResult.InsertedIDs
+	}
+	return
+}
+
 // With replaceOne() you can only replace the entire document,
 // while updateOne() allows for updating fields. Since replaceOne() replaces the entire document - fields in the old document not contained in the new will be lost.
 // With updateOne() new fields can be added without losing the fields in the old document.
@@ -126,6 +184,15 @@ func UpdateDoc(db *mongo.Database, collection string, filter bson.M, updatefield
 	return
 }
 
+// updatefield bisa berupa bson.M operator update atau mongo.Pipeline untuk update berbasis aggregation pipeline
+func UpdateManyDoc(db *mongo.Database, collection string, filter bson.M, updatefield interface{}) (updateresult *mongo.UpdateResult, err error) {
+	updateresult, err = db.Collection(collection).UpdateMany(context.TODO(), filter, updatefield)
+	if err != nil {
+		return
+	}
+	return
+}
+
 func ReplaceOneDoc(db *mongo.Database, collection string, filter bson.M, doc interface{}) (updatereseult *mongo.UpdateResult, err error) {
 	updatereseult, err = db.Collection(collection).ReplaceOne(context.TODO(), filter, doc)
 	if err != nil {
@@ -140,6 +207,17 @@ func DeleteOneDoc(db *mongo.Database, collection string, filter bson.M) (int64,
 	}
 	return result.DeletedCount, nil
 }
+func DeleteManyDocs(db *mongo.Database, collection string, filter bson.M) (int64, error) {
+	result, err := db.Collection(collection).DeleteMany(context.Background(), filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete documents: %w", err)
+	}
+	return result.DeletedCount, nil
+}
 func FindOne(ctx context.Context, collection *mongo.Collection, filter bson.M, result interface{}) error {
 	return collection.FindOne(ctx, filter).Decode(result)
 }
+
+func CreateIndex(db *mongo.Database, collection string, index mongo.IndexModel) (string, error) {
+	return db.Collection(collection).Indexes().CreateOne(context.Background(), index)
+}
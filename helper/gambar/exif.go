@@ -0,0 +1,61 @@
+package gambar
+
+import "encoding/binary"
+
+// orientasiExif membaca tag Orientation (0x0112) di IFD0 segmen APP1 Exif pada JPEG, 1 jika tidak ada
+func orientasiExif(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		//SOS berarti data gambar sudah mulai, segmen metadata tidak ada lagi setelahnya
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		if n < 2 || i+2+n > len(data) {
+			return 1
+		}
+		seg := data[i+4 : i+2+n]
+		if marker == 0xE1 && len(seg) >= 6 && string(seg[:6]) == "Exif\x00\x00" {
+			return orientasiTIFF(seg[6:])
+		}
+		i += 2 + n
+	}
+	return 1
+}
+
+func orientasiTIFF(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var bo binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(bo.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	jumlah := int(bo.Uint16(tiff[ifd:]))
+	for k := 0; k < jumlah; k++ {
+		entry := ifd + 2 + k*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		//tipe 3 adalah SHORT, nilainya ada di 2 byte pertama field value
+		if bo.Uint16(tiff[entry:]) == 0x0112 && bo.Uint16(tiff[entry+2:]) == 3 {
+			return int(bo.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 1
+}
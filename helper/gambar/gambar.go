@@ -0,0 +1,126 @@
+package gambar
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/gocroot/config"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	VarianWeb   = "web"
+	VarianThumb = "thumb"
+)
+
+// Ext adalah ekstensi file semua varian. Upload WebP bisa dibaca, tetapi output WebP belum didukung
+// karena golang.org/x/image hanya punya decoder dan encoder WebP butuh cgo (libwebp) yang tidak tersedia di runtime cloud function.
+const Ext = ".jpg"
+
+var ErrBukanGambar = errors.New("file bukan gambar JPEG, PNG, GIF atau WebP yang bisa dibaca")
+
+var ErrTerlaluBesar = errors.New("dimensi gambar terlalu besar")
+
+// Varian adalah satu hasil encode ulang
+type Varian struct {
+	Nama   string
+	Data   []byte
+	Lebar  int
+	Tinggi int
+}
+
+// Proses mendecode data lalu menghasilkan varian web dan thumb dalam JPEG.
+// Encode ulang membuang semua metadata (EXIF, GPS, profil perangkat), orientasi EXIF diterapkan ke piksel lebih dulu.
+func Proses(data []byte) ([]Varian, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrBukanGambar
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > config.GambarMaxPiksel {
+		return nil, ErrTerlaluBesar
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrBukanGambar
+	}
+
+	//diperkecil dulu baru diputar supaya rotasi per piksel tidak dikerjakan pada resolusi kamera
+	web := putar(skala(src, config.GambarWebMax), orientasiExif(data))
+	thumb := skala(web, config.GambarThumbMax)
+
+	var hasil []Varian
+	for _, v := range []struct {
+		nama string
+		img  image.Image
+	}{{VarianWeb, web}, {VarianThumb, thumb}} {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, v.img, &jpeg.Options{Quality: config.GambarKualitas}); err != nil {
+			return nil, err
+		}
+		b := v.img.Bounds()
+		hasil = append(hasil, Varian{Nama: v.nama, Data: buf.Bytes(), Lebar: b.Dx(), Tinggi: b.Dy()})
+	}
+	return hasil, nil
+}
+
+// skala memperkecil src sampai sisi terpanjangnya maksimal batas dengan latar putih untuk piksel transparan
+func skala(src image.Image, batas int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > batas || h > batas {
+		if w >= h {
+			w, h = batas, h*batas/w
+		} else {
+			w, h = w*batas/h, batas
+		}
+	}
+	//rasio ekstrem seperti 20000x2 bisa membulatkan satu sisi ke 0
+	w, h = max(w, 1), max(h, 1)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+	return dst
+}
+
+// putar menerapkan nilai tag Orientation EXIF (1-8) sehingga gambar tampil tegak tanpa metadata
+func putar(src *image.RGBA, orientasi int) *image.RGBA {
+	if orientasi < 2 || orientasi > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientasi >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientasi {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.SetRGBA(dx, dy, src.RGBAAt(x, y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,127 @@
+package gambar
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func jpegUji(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// sisipExif menaruh segmen APP1 Exif berisi satu tag Orientation tepat setelah SOI
+func sisipExif(data []byte, littleEndian bool, orientasi uint16) []byte {
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1, 0x01, 0x12, 0, 3, 0, 0, 0, 1, byte(orientasi >> 8), byte(orientasi), 0, 0, 0, 0, 0, 0}
+	if littleEndian {
+		tiff = []byte{'I', 'I', 42, 0, 8, 0, 0, 0, 1, 0, 0x12, 0x01, 3, 0, 1, 0, 0, 0, byte(orientasi), byte(orientasi >> 8), 0, 0, 0, 0, 0, 0}
+	}
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+	n := len(seg) + 2
+	app1 := append([]byte{0xFF, 0xE1, byte(n >> 8), byte(n)}, seg...)
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	return append(out, data[2:]...)
+}
+
+func TestOrientasiExif(t *testing.T) {
+	polos := jpegUji(t, 4, 2)
+	cases := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"tanpa exif", polos, 1},
+		{"big endian", sisipExif(polos, false, 6), 6},
+		{"little endian", sisipExif(polos, true, 8), 8},
+		{"bukan jpeg", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"kosong", nil, 1},
+		{"segmen terpotong", sisipExif(polos, false, 6)[:12], 1},
+	}
+	for _, c := range cases {
+		if got := orientasiExif(c.data); got != c.want {
+			t.Errorf("%s: orientasiExif = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestProses(t *testing.T) {
+	data := sisipExif(jpegUji(t, 1600, 800), false, 6)
+	hasil, err := Proses(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hasil) != 2 || hasil[0].Nama != VarianWeb || hasil[1].Nama != VarianThumb {
+		t.Fatalf("varian = %+v", hasil)
+	}
+	//orientasi 6 memutar 90 derajat sehingga lanskap menjadi potret
+	if hasil[0].Lebar != 800 || hasil[0].Tinggi != 1600 {
+		t.Errorf("web = %dx%d, want 800x1600", hasil[0].Lebar, hasil[0].Tinggi)
+	}
+	if hasil[1].Lebar != 160 || hasil[1].Tinggi != 320 {
+		t.Errorf("thumb = %dx%d, want 160x320", hasil[1].Lebar, hasil[1].Tinggi)
+	}
+	for _, v := range hasil {
+		if bytes.Contains(v.Data, []byte("Exif")) {
+			t.Errorf("%s masih berisi EXIF", v.Nama)
+		}
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(v.Data))
+		if err != nil || format != "jpeg" || cfg.Width != v.Lebar || cfg.Height != v.Tinggi {
+			t.Errorf("%s: decode %s %dx%d, err %v", v.Nama, format, cfg.Width, cfg.Height, err)
+		}
+	}
+}
+
+func TestProsesRasioEkstrem(t *testing.T) {
+	hasil, err := Proses(jpegUji(t, 4000, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range hasil {
+		if v.Lebar < 1 || v.Tinggi < 1 {
+			t.Errorf("%s = %dx%d, sisi tidak boleh nol", v.Nama, v.Lebar, v.Tinggi)
+		}
+	}
+}
+
+func TestProsesPNGTransparan(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img.Set(0, 0, color.NRGBA{A: 0})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	hasil, err := Proses(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	web, err := jpeg.Decode(bytes.NewReader(hasil[0].Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	//piksel transparan jatuh ke latar putih, bukan hitam
+	if r, _, _, _ := web.At(0, 0).RGBA(); r < 0xF000 {
+		t.Errorf("piksel transparan r = %#x, want putih", r)
+	}
+}
+
+func TestProsesBukanGambar(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("bukan gambar"), jpegUji(t, 4, 2)[:20]} {
+		if _, err := Proses(data); !errors.Is(err, ErrBukanGambar) {
+			t.Errorf("Proses(%d byte) err = %v, want ErrBukanGambar", len(data), err)
+		}
+	}
+}
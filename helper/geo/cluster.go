@@ -0,0 +1,38 @@
+package geo
+
+import (
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// batas lintang Web Mercator, di luar ini proyeksi menuju tak hingga
+const mercatorMaxLat = 85.05112878
+
+// GridSel adalah jumlah sel grid per sumbu untuk sel berukuran gridPx piksel pada tile 256 piksel di level zoom tersebut
+func GridSel(zoom int, gridPx float64) float64 {
+	return math.Exp2(float64(zoom)) * 256 / gridPx
+}
+
+// GridKey adalah ekspresi aggregation {x, y} nomor sel grid dari field lon/lat, dipakai sebagai _id di $group.
+// Grid dihitung pada proyeksi Web Mercator supaya sel terlihat persegi di peta.
+func GridKey(zoom int, gridPx float64) bson.D {
+	sel := GridSel(zoom, gridPx)
+	lat := bson.M{"$max": bson.A{bson.M{"$min": bson.A{"$lat", mercatorMaxLat}}, -mercatorMaxLat}}
+	//x = (lon+180)/360, y = 0.5 - ln((1+sin)/(1-sin))/(4π), keduanya ternormalisasi 0..1
+	x := bson.M{"$divide": bson.A{bson.M{"$add": bson.A{"$lon", 180}}, 360}}
+	y := bson.M{"$let": bson.M{
+		"vars": bson.M{"sin": bson.M{"$sin": bson.M{"$degreesToRadians": lat}}},
+		"in": bson.M{"$subtract": bson.A{0.5, bson.M{"$divide": bson.A{
+			bson.M{"$ln": bson.M{"$divide": bson.A{
+				bson.M{"$add": bson.A{1, "$$sin"}},
+				bson.M{"$subtract": bson.A{1, "$$sin"}},
+			}}},
+			4 * math.Pi,
+		}}}},
+	}}
+	return bson.D{
+		{Key: "x", Value: bson.M{"$floor": bson.M{"$multiply": bson.A{x, sel}}}},
+		{Key: "y", Value: bson.M{"$floor": bson.M{"$multiply": bson.A{y, sel}}}},
+	}
+}
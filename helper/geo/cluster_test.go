@@ -0,0 +1,40 @@
+package geo
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGridSel(t *testing.T) {
+	tests := []struct {
+		zoom   int
+		gridPx float64
+		want   float64
+	}{
+		//zoom 0 dengan sel 256 piksel berarti seluruh dunia satu sel
+		{0, 256, 1},
+		{0, 64, 4},
+		{1, 256, 2},
+		{10, 64, 4096},
+	}
+	for _, tt := range tests {
+		if got := GridSel(tt.zoom, tt.gridPx); got != tt.want {
+			t.Errorf("GridSel(%d, %v) = %v, want %v", tt.zoom, tt.gridPx, got, tt.want)
+		}
+	}
+}
+
+func TestGridKey(t *testing.T) {
+	key := GridKey(3, 64)
+	if len(key) != 2 || key[0].Key != "x" || key[1].Key != "y" {
+		t.Fatalf("GridKey = %v, want field x dan y", key)
+	}
+	//jumlah sel ikut dikalikan supaya sel grid berubah sesuai zoom
+	for _, e := range key {
+		kali := e.Value.(bson.M)["$floor"].(bson.M)["$multiply"].(bson.A)
+		if kali[1] != GridSel(3, 64) {
+			t.Errorf("%s dikalikan %v, want %v", e.Key, kali[1], GridSel(3, 64))
+		}
+	}
+}
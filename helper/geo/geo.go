@@ -0,0 +1,103 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gocroot/model"
+)
+
+// NewPoint membuat GeoJSON Point, urutan koordinat GeoJSON adalah [lon, lat]
+func NewPoint(lon, lat float64) *model.Point {
+	return &model.Point{
+		Type:        "Point",
+		Coordinates: []float64{lon, lat},
+	}
+}
+
+func ValidLonLat(lon, lat float64) bool {
+	return lon >= -180 && lon <= 180 && lat >= -90 && lat <= 90
+}
+
+// BBox adalah batas viewport peta dalam derajat, urutan mengikuti format bbox GeoJSON
+type BBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// ParseBBox membaca string "minLon,minLat,maxLon,maxLat"
+// minLon boleh lebih besar dari maxLon untuk viewport yang melewati garis 180 derajat
+func ParseBBox(s string) (box BBox, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		err = errors.New("bbox harus berformat minLon,minLat,maxLon,maxLat")
+		return
+	}
+	var val [4]float64
+	for i, part := range parts {
+		val[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			err = fmt.Errorf("bbox berisi angka tidak valid: %s", part)
+			return
+		}
+	}
+	box = BBox{MinLon: val[0], MinLat: val[1], MaxLon: val[2], MaxLat: val[3]}
+	if !ValidLonLat(box.MinLon, box.MinLat) || !ValidLonLat(box.MaxLon, box.MaxLat) {
+		err = errors.New("bbox di luar rentang koordinat")
+		return
+	}
+	if box.MinLat > box.MaxLat {
+		err = errors.New("bbox minLat lebih besar dari maxLat")
+		return
+	}
+	return
+}
+
+// CrossesAntimeridian true jika viewport melewati garis 180 derajat
+func (b BBox) CrossesAntimeridian() bool {
+	return b.MinLon > b.MaxLon
+}
+
+func (b BBox) Contains(lon, lat float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return lon >= b.MinLon || lon <= b.MaxLon
+	}
+	return lon >= b.MinLon && lon <= b.MaxLon
+}
+
+const radiusBumi = 6371008.8
+
+// Haversine menghitung jarak permukaan bumi dalam meter
+func Haversine(lon1, lat1, lon2, lat2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * radiusBumi * math.Asin(math.Sqrt(a))
+}
+
+// MeterKeDerajatLat mengubah jarak utara-selatan dalam meter ke derajat lintang
+func MeterKeDerajatLat(meter float64) float64 {
+	return meter / (radiusBumi * math.Pi / 180)
+}
+
+// DalamPoligon memakai ray casting, ring berisi titik [lon, lat] dan boleh tertutup atau tidak
+func DalamPoligon(lon, lat float64, ring [][]float64) bool {
+	dalam := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			dalam = !dalam
+		}
+	}
+	return dalam
+}
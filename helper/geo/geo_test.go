@@ -0,0 +1,104 @@
+package geo
+
+import "testing"
+
+func TestParseBBox(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    BBox
+		wantErr bool
+	}{
+		{input: "106.7,-6.3,106.9,-6.1", want: BBox{MinLon: 106.7, MinLat: -6.3, MaxLon: 106.9, MaxLat: -6.1}},
+		{input: " 106.7 , -6.3 , 106.9 , -6.1 ", want: BBox{MinLon: 106.7, MinLat: -6.3, MaxLon: 106.9, MaxLat: -6.1}},
+		{input: "170,-10,-170,10", want: BBox{MinLon: 170, MinLat: -10, MaxLon: -170, MaxLat: 10}},
+		{input: "106.7,-6.3,106.9", wantErr: true},
+		{input: "106.7,-6.3,106.9,-6.1,0", wantErr: true},
+		{input: "a,-6.3,106.9,-6.1", wantErr: true},
+		{input: "181,-6.3,106.9,-6.1", wantErr: true},
+		{input: "106.7,-91,106.9,-6.1", wantErr: true},
+		{input: "106.7,-6.1,106.9,-6.3", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBBox(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBBox(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseBBox(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestBBoxContains(t *testing.T) {
+	jakarta := BBox{MinLon: 106.7, MinLat: -6.3, MaxLon: 106.9, MaxLat: -6.1}
+	antimeridian := BBox{MinLon: 170, MinLat: -10, MaxLon: -170, MaxLat: 10}
+	tests := []struct {
+		box      BBox
+		lon, lat float64
+		want     bool
+	}{
+		{jakarta, 106.8, -6.2, true},
+		{jakarta, 106.7, -6.3, true},
+		{jakarta, 107.0, -6.2, false},
+		{jakarta, 106.8, -6.0, false},
+		{antimeridian, 175, 0, true},
+		{antimeridian, -175, 0, true},
+		{antimeridian, 0, 0, false},
+		{antimeridian, 175, 11, false},
+	}
+	for _, tt := range tests {
+		if got := tt.box.Contains(tt.lon, tt.lat); got != tt.want {
+			t.Errorf("%+v.Contains(%v, %v) = %v, want %v", tt.box, tt.lon, tt.lat, got, tt.want)
+		}
+	}
+}
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lon1, lat1, lon2, lat2 float64
+		want, toleransi        float64
+	}{
+		{"titik sama", 106.8272, -6.1754, 106.8272, -6.1754, 0, 0},
+		{"satu derajat lintang", 0, 0, 0, 1, 111195, 1},
+		{"Monas ke Gedung Sate", 106.8272, -6.1754, 107.6186, -6.9025, 119000, 1000},
+		{"melewati garis 180", 179.5, 0, -179.5, 0, 111195, 1},
+	}
+	for _, tt := range tests {
+		got := Haversine(tt.lon1, tt.lat1, tt.lon2, tt.lat2)
+		if got < tt.want-tt.toleransi || got > tt.want+tt.toleransi {
+			t.Errorf("%s: Haversine = %.1f, want %.0f ± %.0f", tt.name, got, tt.want, tt.toleransi)
+		}
+	}
+	if got := MeterKeDerajatLat(111195); got < 0.9999 || got > 1.0001 {
+		t.Errorf("MeterKeDerajatLat(111195) = %v, want ~1", got)
+	}
+}
+
+func TestDalamPoligon(t *testing.T) {
+	persegi := [][]float64{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	terbuka := [][]float64{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	//bentuk L, titik di lekukan berada di luar
+	bentukL := [][]float64{{0, 0}, {10, 0}, {10, 4}, {4, 4}, {4, 10}, {0, 10}}
+	tests := []struct {
+		name     string
+		lon, lat float64
+		ring     [][]float64
+		want     bool
+	}{
+		{"tengah", 5, 5, persegi, true},
+		{"kiri luar", -1, 5, persegi, false},
+		{"atas luar", 5, 11, persegi, false},
+		{"ring tidak ditutup", 5, 5, terbuka, true},
+		{"kaki L", 2, 8, bentukL, true},
+		{"lekukan L", 7, 7, bentukL, false},
+		{"ring kosong", 5, 5, nil, false},
+	}
+	for _, tt := range tests {
+		if got := DalamPoligon(tt.lon, tt.lat, tt.ring); got != tt.want {
+			t.Errorf("%s: DalamPoligon(%v, %v) = %v, want %v", tt.name, tt.lon, tt.lat, got, tt.want)
+		}
+	}
+}
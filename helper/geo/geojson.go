@@ -0,0 +1,54 @@
+package geo
+
+import (
+	"github.com/gocroot/model"
+)
+
+func TempatFeature(tempat model.Tempat) model.Feature {
+	feature := model.Feature{
+		Type:     "Feature",
+		Geometry: NewPoint(tempat.Lon, tempat.Lat),
+		Properties: model.TempatProperties{
+			Nama_Tempat: tempat.Nama_Tempat,
+			Lokasi:      tempat.Lokasi,
+			Fasilitas:   tempat.Fasilitas,
+			Gambar:      tempat.Gambar,
+			Rating:      tempat.Rating,
+			Status:      tempat.Status,
+		},
+	}
+	if !tempat.ID.IsZero() {
+		feature.ID = tempat.ID.Hex()
+	}
+	return feature
+}
+
+func TempatFeatureCollection(tempat []model.Tempat) model.FeatureCollection {
+	fc := model.FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]model.Feature, 0, len(tempat)),
+	}
+	for _, t := range tempat {
+		fc.Features = append(fc.Features, TempatFeature(t))
+	}
+	return fc
+}
+
+// KoordinatFeatureCollection mengubah array marker [lon, lat] menjadi Point, pasangan yang tidak lengkap dilewati
+func KoordinatFeatureCollection(koordinat model.Koordinat) model.FeatureCollection {
+	fc := model.FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]model.Feature, 0, len(koordinat.Markers)),
+	}
+	for _, marker := range koordinat.Markers {
+		if len(marker) < 2 {
+			continue
+		}
+		fc.Features = append(fc.Features, model.Feature{
+			Type:       "Feature",
+			Geometry:   NewPoint(marker[0], marker[1]),
+			Properties: map[string]interface{}{},
+		})
+	}
+	return fc
+}
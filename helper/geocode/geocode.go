@@ -0,0 +1,72 @@
+package geocode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// kategori poligon di koleksi lokasi yang dipakai untuk wilayah administrasi
+const (
+	KategoriKelurahan = "kelurahan"
+	KategoriKecamatan = "kecamatan"
+	KategoriKota      = "kota"
+)
+
+var KategoriWilayah = []string{KategoriKelurahan, KategoriKecamatan, KategoriKota}
+
+func pointFilter(long float64, lat float64) bson.M {
+	return bson.M{
+		"batas": bson.M{
+			"$geoIntersects": bson.M{
+				"$geometry": bson.M{
+					"type":        "Point",
+					"coordinates": []float64{long, lat},
+				},
+			},
+		},
+	}
+}
+
+// GetLokasi mengembalikan nama poligon lokasi absen pertama yang memuat titik, poligon wilayah administrasi dilewati
+func GetLokasi(mongoconn *mongo.Database, long float64, lat float64) (namalokasi string) {
+	lokasicollection := mongoconn.Collection("lokasi")
+	filter := pointFilter(long, lat)
+	filter["kategori"] = bson.M{"$nin": KategoriWilayah}
+	var lokasi model.Lokasi
+	err := lokasicollection.FindOne(context.TODO(), filter).Decode(&lokasi)
+	if err != nil {
+		fmt.Printf("GetLokasi: %v\n", err)
+	}
+	return lokasi.Nama
+}
+
+// ReverseGeocode mengisi kelurahan, kecamatan dan kota dari poligon yang memuat titik.
+// Hasil nil jika tidak ada poligon administrasi yang cocok.
+func ReverseGeocode(mongoconn *mongo.Database, long float64, lat float64) (*model.Wilayah, error) {
+	filter := pointFilter(long, lat)
+	filter["kategori"] = bson.M{"$in": KategoriWilayah}
+	lokasi, err := atdb.GetAllDoc[[]model.Lokasi](mongoconn, "lokasi", filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(lokasi) == 0 {
+		return nil, nil
+	}
+	var wilayah model.Wilayah
+	for _, l := range lokasi {
+		switch l.Kategori {
+		case KategoriKelurahan:
+			wilayah.Kelurahan = l.Nama
+		case KategoriKecamatan:
+			wilayah.Kecamatan = l.Nama
+		case KategoriKota:
+			wilayah.Kota = l.Nama
+		}
+	}
+	return &wilayah, nil
+}
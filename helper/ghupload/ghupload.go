@@ -22,7 +22,11 @@ func GithubUpload(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail string,
 	if err != nil {
 		return
 	}
+	return GithubUploadBytes(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail, fileContent, githubOrg, githubRepo, pathFile, replace)
+}
 
+// GithubUploadBytes sama dengan GithubUpload tetapi isi file sudah di memori, misalnya hasil encode ulang gambar
+func GithubUploadBytes(GitHubAccessToken, GitHubAuthorName, GitHubAuthorEmail string, fileContent []byte, githubOrg string, githubRepo string, pathFile string, replace bool) (content *github.RepositoryContentResponse, response *github.Response, err error) {
 	// Konfigurasi koneksi ke GitHub menggunakan token akses
 	ctx := context.Background()
 	ts := oauth2.StaticTokenSource(
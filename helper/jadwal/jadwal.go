@@ -0,0 +1,183 @@
+package jadwal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" //runtime cloud function belum tentu punya database zona waktu
+
+	"github.com/gocroot/model"
+)
+
+const (
+	StatusBuka           = "open"
+	StatusTutup          = "closed"
+	StatusSegeraTutup    = "closing_soon"
+	StatusTidakDiketahui = "unknown"
+)
+
+const formatTanggal = "2006-01-02"
+
+// Zona adalah zona waktu acuan semua jadwal
+var Zona = loadZona()
+
+func loadZona() *time.Location {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}
+
+type rentang struct {
+	mulai   time.Time
+	selesai time.Time
+}
+
+// Status menghitung status buka pada waktu t, closing_soon jika akan tutup dalam durasi segera.
+// Tempat tanpa jadwal yang berlaku pada hari itu dianggap unknown.
+func Status(j *model.JamBuka, t time.Time, segera time.Duration) string {
+	t = t.In(Zona)
+	hariIni := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, Zona)
+	if j == nil || (len(j.Mingguan) == 0 && len(pengecualian(j, hariIni)) == 0) {
+		return StatusTidakDiketahui
+	}
+
+	//rentang kemarin dibutuhkan untuk jadwal yang melewati tengah malam, besok untuk menyambung jadwal 24 jam
+	var semua []rentang
+	for d := -1; d <= 1; d++ {
+		semua = append(semua, rentangHari(j, hariIni.AddDate(0, 0, d))...)
+	}
+	sort.Slice(semua, func(a, b int) bool { return semua[a].mulai.Before(semua[b].mulai) })
+
+	for _, r := range semua {
+		if t.Before(r.mulai) || !t.Before(r.selesai) {
+			continue
+		}
+		tutup := r.selesai
+		for _, next := range semua {
+			if !next.mulai.After(tutup) && next.selesai.After(tutup) {
+				tutup = next.selesai
+			}
+		}
+		if tutup.Sub(t) <= segera {
+			return StatusSegeraTutup
+		}
+		return StatusBuka
+	}
+	return StatusTutup
+}
+
+// Buka true jika status pada waktu t adalah open atau closing_soon
+func Buka(j *model.JamBuka, t time.Time) bool {
+	status := Status(j, t, 0)
+	return status == StatusBuka || status == StatusSegeraTutup
+}
+
+func pengecualian(j *model.JamBuka, hari time.Time) (hasil []model.JadwalPengecualian) {
+	tanggal := hari.Format(formatTanggal)
+	for _, p := range j.Pengecualian {
+		if p.Tanggal == tanggal {
+			hasil = append(hasil, p)
+		}
+	}
+	return
+}
+
+func rentangHari(j *model.JamBuka, hari time.Time) (hasil []rentang) {
+	if khusus := pengecualian(j, hari); len(khusus) > 0 {
+		for _, p := range khusus {
+			if !p.Libur {
+				hasil = append(hasil, buatRentang(hari, p.Buka, p.Tutup))
+			}
+		}
+		return
+	}
+	for _, h := range j.Mingguan {
+		if time.Weekday(h.Hari) == hari.Weekday() {
+			hasil = append(hasil, buatRentang(hari, h.Buka, h.Tutup))
+		}
+	}
+	return
+}
+
+func buatRentang(hari time.Time, buka, tutup string) rentang {
+	mb, _ := parseJam(buka)
+	mt, _ := parseJam(tutup)
+	r := rentang{
+		mulai:   hari.Add(time.Duration(mb) * time.Minute),
+		selesai: hari.Add(time.Duration(mt) * time.Minute),
+	}
+	if mt <= mb {
+		r.selesai = r.selesai.Add(24 * time.Hour)
+	}
+	return r
+}
+
+// parseJam mengubah "15:04" menjadi menit sejak tengah malam, "24:00" diterima sebagai akhir hari
+func parseJam(s string) (int, error) {
+	jam, menit, ok := strings.Cut(s, ":")
+	h, errH := strconv.Atoi(jam)
+	m, errM := strconv.Atoi(menit)
+	if !ok || len(jam) != 2 || len(menit) != 2 || errH != nil || errM != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("jam %q harus berformat HH:MM", s)
+	}
+	return h*60 + m, nil
+}
+
+// Validate memeriksa format hari, jam dan tanggal pada jadwal
+func Validate(j *model.JamBuka) error {
+	if j == nil {
+		return nil
+	}
+	for _, h := range j.Mingguan {
+		if h.Hari < 0 || h.Hari > 6 {
+			return errors.New("hari harus 0 (Minggu) sampai 6 (Sabtu)")
+		}
+		if err := validateRentang(h.Buka, h.Tutup); err != nil {
+			return err
+		}
+	}
+	for _, p := range j.Pengecualian {
+		if _, err := time.Parse(formatTanggal, p.Tanggal); err != nil {
+			return fmt.Errorf("tanggal %q harus berformat YYYY-MM-DD", p.Tanggal)
+		}
+		if p.Libur {
+			continue
+		}
+		if err := validateRentang(p.Buka, p.Tutup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRentang(buka, tutup string) error {
+	mb, err := parseJam(buka)
+	if err != nil {
+		return err
+	}
+	mt, err := parseJam(tutup)
+	if err != nil {
+		return err
+	}
+	if mb == mt {
+		return fmt.Errorf("jam buka dan tutup tidak boleh sama (%s)", buka)
+	}
+	return nil
+}
+
+// ParseWaktu membaca waktu RFC3339 atau "2006-01-02T15:04" yang dianggap dalam zona Asia/Jakarta
+func ParseWaktu(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04", s, Zona)
+	if err != nil {
+		return t, errors.New("waktu harus berformat RFC3339 atau YYYY-MM-DDTHH:MM")
+	}
+	return t, nil
+}
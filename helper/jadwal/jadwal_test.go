@@ -0,0 +1,114 @@
+package jadwal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocroot/model"
+)
+
+// 3 Juni 2024 adalah hari Senin
+func wib(hari, jam, menit int) time.Time {
+	return time.Date(2024, 6, hari, jam, menit, 0, 0, Zona)
+}
+
+func TestStatus(t *testing.T) {
+	kantor := &model.JamBuka{Mingguan: []model.JadwalHarian{{Hari: 1, Buka: "08:00", Tutup: "17:00"}}}
+	malam := &model.JamBuka{Mingguan: []model.JadwalHarian{{Hari: 5, Buka: "22:00", Tutup: "02:00"}}}
+	seharian := &model.JamBuka{}
+	for hari := 0; hari <= 6; hari++ {
+		seharian.Mingguan = append(seharian.Mingguan, model.JadwalHarian{Hari: hari, Buka: "00:00", Tutup: "24:00"})
+	}
+	libur := &model.JamBuka{
+		Mingguan:     kantor.Mingguan,
+		Pengecualian: []model.JadwalPengecualian{{Tanggal: "2024-06-03", Libur: true}},
+	}
+	khusus := &model.JamBuka{Pengecualian: []model.JadwalPengecualian{{Tanggal: "2024-06-04", Buka: "10:00", Tutup: "12:00"}}}
+
+	tests := []struct {
+		name   string
+		jadwal *model.JamBuka
+		waktu  time.Time
+		want   string
+	}{
+		{"tanpa jadwal", nil, wib(3, 10, 0), StatusTidakDiketahui},
+		{"jam kerja", kantor, wib(3, 10, 0), StatusBuka},
+		{"sebelum buka", kantor, wib(3, 7, 59), StatusTutup},
+		{"tepat jam tutup", kantor, wib(3, 17, 0), StatusTutup},
+		{"segera tutup", kantor, wib(3, 16, 45), StatusSegeraTutup},
+		{"hari lain", kantor, wib(4, 10, 0), StatusTutup},
+		{"lewat tengah malam dari kemarin", malam, wib(8, 1, 0), StatusBuka},
+		{"lewat tengah malam sudah tutup", malam, wib(8, 2, 30), StatusTutup},
+		{"malam sebelum buka", malam, wib(7, 21, 0), StatusTutup},
+		{"24 jam menyambung ke besok", seharian, wib(3, 23, 50), StatusBuka},
+		{"pengecualian libur", libur, wib(3, 10, 0), StatusTutup},
+		{"pengecualian jam khusus", khusus, wib(4, 11, 0), StatusBuka},
+		{"pengecualian di luar jam khusus", khusus, wib(4, 13, 0), StatusTutup},
+		{"hanya pengecualian hari lain", khusus, wib(5, 11, 0), StatusTidakDiketahui},
+		{"input UTC dikonversi ke WIB", kantor, time.Date(2024, 6, 3, 3, 0, 0, 0, time.UTC), StatusBuka},
+		{"input UTC sudah lewat jam tutup WIB", kantor, time.Date(2024, 6, 3, 10, 30, 0, 0, time.UTC), StatusTutup},
+	}
+	for _, tt := range tests {
+		if got := Status(tt.jadwal, tt.waktu, 30*time.Minute); got != tt.want {
+			t.Errorf("%s: Status = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuka(t *testing.T) {
+	kantor := &model.JamBuka{Mingguan: []model.JadwalHarian{{Hari: 1, Buka: "08:00", Tutup: "17:00"}}}
+	if !Buka(kantor, wib(3, 16, 59)) {
+		t.Error("Buka 16:59 = false, want true")
+	}
+	if Buka(kantor, wib(3, 17, 0)) {
+		t.Error("Buka 17:00 = true, want false")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		jadwal  *model.JamBuka
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"valid", &model.JamBuka{Mingguan: []model.JadwalHarian{{Hari: 0, Buka: "00:00", Tutup: "24:00"}}}, false},
+		{"lewat tengah malam", &model.JamBuka{Mingguan: []model.JadwalHarian{{Hari: 6, Buka: "22:00", Tutup: "02:00"}}}, false},
+		{"libur tanpa jam", &model.JamBuka{Pengecualian: []model.JadwalPengecualian{{Tanggal: "2024-08-17", Libur: true}}}, false},
+		{"hari di luar rentang", &model.JamBuka{Mingguan: []model.JadwalHarian{{Hari: 7, Buka: "08:00", Tutup: "17:00"}}}, true},
+		{"jam satu digit", &model.JamBuka{Mingguan: []model.JadwalHarian{{Hari: 1, Buka: "8:00", Tutup: "17:00"}}}, true},
+		{"lewat 24:00", &model.JamBuka{Mingguan: []model.JadwalHarian{{Hari: 1, Buka: "08:00", Tutup: "24:30"}}}, true},
+		{"menit 60", &model.JamBuka{Mingguan: []model.JadwalHarian{{Hari: 1, Buka: "08:60", Tutup: "17:00"}}}, true},
+		{"buka sama dengan tutup", &model.JamBuka{Mingguan: []model.JadwalHarian{{Hari: 1, Buka: "08:00", Tutup: "08:00"}}}, true},
+		{"tanggal salah", &model.JamBuka{Pengecualian: []model.JadwalPengecualian{{Tanggal: "17-08-2024", Libur: true}}}, true},
+		{"pengecualian tanpa jam", &model.JamBuka{Pengecualian: []model.JadwalPengecualian{{Tanggal: "2024-08-17"}}}, true},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.jadwal); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseWaktu(t *testing.T) {
+	got, err := ParseWaktu("2024-06-03T10:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//tanpa zona dianggap Asia/Jakarta (UTC+7)
+	if want := time.Date(2024, 6, 3, 3, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ParseWaktu lokal = %v, want %v", got, want)
+	}
+	got, err = ParseWaktu("2024-06-03T10:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ParseWaktu RFC3339 = %v, want %v", got, want)
+	}
+	for _, s := range []string{"", "03-06-2024 10:00", "2024-06-03", "2024-06-03T25:00"} {
+		if _, err := ParseWaktu(s); err == nil {
+			t.Errorf("ParseWaktu(%q) error = nil, want error", s)
+		}
+	}
+}
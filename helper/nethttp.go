@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strings"
 )
 
 func GetSecretFromHeader(r *http.Request) (secret string) {
@@ -38,6 +39,17 @@ func WriteJSON(respw http.ResponseWriter, statusCode int, content interface{}) {
 	respw.Write([]byte(Jsonstr(content)))
 }
 
+func WriteGeoJSON(respw http.ResponseWriter, statusCode int, content interface{}) {
+	respw.Header().Set("Content-Type", "application/geo+json")
+	respw.WriteHeader(statusCode)
+	respw.Write([]byte(Jsonstr(content)))
+}
+
+// WantGeoJSON true jika client meminta GeoJSON lewat header Accept atau akhiran .geojson pada path
+func WantGeoJSON(r *http.Request) bool {
+	return strings.HasSuffix(r.URL.Path, ".geojson") || strings.Contains(r.Header.Get("Accept"), "application/geo+json")
+}
+
 func WriteString(respw http.ResponseWriter, statusCode int, content string) {
 	respw.WriteHeader(statusCode)
 	respw.Write([]byte(content))
@@ -0,0 +1,72 @@
+package teks
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// SearchTerms memecah query pencarian menjadi kata, mengabaikan tanda kutip dan kata negasi "-kata"
+func SearchTerms(q string) (terms []string) {
+	for _, term := range strings.Fields(q) {
+		if strings.HasPrefix(term, "-") {
+			continue
+		}
+		term = strings.Trim(term, `"'`)
+		if term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return
+}
+
+// Highlight mengembalikan potongan text sejauh radius karakter di sekitar kata pertama yang cocok, setiap kata yang cocok dibungkus <mark>.
+// Text di-escape HTML sehingga aman ditampilkan langsung. String kosong jika tidak ada yang cocok.
+func Highlight(text string, terms []string, radius int) string {
+	if text == "" || len(terms) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	re := regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+	first := re.FindStringIndex(text)
+	if first == nil {
+		return ""
+	}
+
+	//radius dihitung dalam rune supaya potongan tidak pernah membelah karakter multibyte
+	start, end := first[0], first[1]
+	for n := 0; n < radius && start > 0; n++ {
+		_, size := utf8.DecodeLastRuneInString(text[:start])
+		start -= size
+	}
+	for n := 0; n < radius && end < len(text); n++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+	prefix, suffix := "", ""
+	if start > 0 {
+		prefix = "…"
+	}
+	if end < len(text) {
+		suffix = "…"
+	}
+	snippet := text[start:end]
+
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	last := 0
+	for _, loc := range re.FindAllStringIndex(snippet, -1) {
+		sb.WriteString(html.EscapeString(snippet[last:loc[0]]))
+		sb.WriteString("<mark>")
+		sb.WriteString(html.EscapeString(snippet[loc[0]:loc[1]]))
+		sb.WriteString("</mark>")
+		last = loc[1]
+	}
+	sb.WriteString(html.EscapeString(snippet[last:]))
+	sb.WriteString(suffix)
+	return sb.String()
+}
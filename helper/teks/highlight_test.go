@@ -0,0 +1,45 @@
+package teks
+
+import (
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSearchTerms(t *testing.T) {
+	got := SearchTerms(`parkir -berbayar "monas" ''`)
+	if want := []string{"parkir", "monas"}; !slices.Equal(got, want) {
+		t.Errorf("SearchTerms = %q, want %q", got, want)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		terms  []string
+		radius int
+		want   string
+	}{
+		{"utuh", "Parkir Gratis Monas", []string{"monas"}, 60, "Parkir Gratis <mark>Monas</mark>"},
+		{"beberapa kata", "Parkir gratis dekat parkir motor", []string{"parkir"}, 60, "<mark>Parkir</mark> gratis dekat <mark>parkir</mark> motor"},
+		{"escape html", "A & <b> parkir", []string{"parkir"}, 60, "A &amp; &lt;b&gt; <mark>parkir</mark>"},
+		{"dipotong", "aaaaaaaaaa parkir bbbbbbbbbb", []string{"parkir"}, 3, "…aa <mark>parkir</mark> bb…"},
+		{"multibyte", "ééé parkir", []string{"parkir"}, 2, "…é <mark>parkir</mark>"},
+		{"radius dalam rune", "日本語 parkir 日本語", []string{"parkir"}, 2, "…語 <mark>parkir</mark> 日…"},
+		{"multibyte di akhir", "parkir ñññ", []string{"parkir"}, 3, "<mark>parkir</mark> ññ…"},
+		{"regex di-quote", "tarif 2.000 (flat)", []string{"(flat)"}, 60, "tarif 2.000 <mark>(flat)</mark>"},
+		{"tidak cocok", "Parkir Gratis Monas", []string{"kota"}, 60, ""},
+		{"tanpa term", "Parkir Gratis Monas", nil, 60, ""},
+		{"text kosong", "", []string{"parkir"}, 60, ""},
+	}
+	for _, tt := range tests {
+		got := Highlight(tt.text, tt.terms, tt.radius)
+		if got != tt.want {
+			t.Errorf("%s: Highlight = %q, want %q", tt.name, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("%s: Highlight menghasilkan UTF-8 tidak valid %q", tt.name, got)
+		}
+	}
+}
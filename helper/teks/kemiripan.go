@@ -0,0 +1,69 @@
+package teks
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize mengecilkan huruf, membuang tanda baca dan merapikan spasi
+func Normalize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Kemiripan mengembalikan nilai 0..1, nilai tertinggi antara rasio Levenshtein dan Jaccard kata.
+// Jaccard menangani urutan kata berbeda ("Masjid Raya Parkir" dan "Parkir Masjid Raya").
+func Kemiripan(a, b string) float64 {
+	a, b = Normalize(a), Normalize(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	return max(rasioLevenshtein(a, b), jaccardKata(a, b))
+}
+
+func rasioLevenshtein(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+func jaccardKata(a, b string) float64 {
+	setA := map[string]bool{}
+	for _, w := range strings.Fields(a) {
+		setA[w] = true
+	}
+	setB := map[string]bool{}
+	for _, w := range strings.Fields(b) {
+		setB[w] = true
+	}
+	irisan := 0
+	for w := range setA {
+		if setB[w] {
+			irisan++
+		}
+	}
+	return float64(irisan) / float64(len(setA)+len(setB)-irisan)
+}
@@ -0,0 +1,36 @@
+package teks
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("  Parkir-Gratis,  MONAS!! "); got != "parkir gratis monas" {
+		t.Errorf("Normalize = %q", got)
+	}
+}
+
+func TestKemiripan(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Parkir Monas", "parkir  MONAS!", 1},
+		{"Masjid Raya Parkir", "Parkir Masjid Raya", 1},
+		{"parkir", "parkit", 1 - 1.0/6},
+		{"Parkir Monas", "Parkir Kota Tua", 0.6}, //Levenshtein 6 dari 15 karakter, lebih tinggi dari Jaccard 1/4
+		{"abc", "xyz", 0},
+		{"", "parkir", 0},
+		{"!!!", "???", 0},
+	}
+	for _, tt := range tests {
+		got := Kemiripan(tt.a, tt.b)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Kemiripan(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if balik := Kemiripan(tt.b, tt.a); math.Abs(balik-got) > 1e-9 {
+			t.Errorf("Kemiripan tidak simetris untuk %q dan %q: %v vs %v", tt.a, tt.b, got, balik)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package validasi
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gocroot/config"
+	"github.com/gocroot/helper/geo"
+	"github.com/gocroot/helper/jadwal"
+	"github.com/gocroot/model"
+)
+
+// Errors mengumpulkan semua kesalahan field supaya bisa dilaporkan sekaligus
+type Errors []model.FieldError
+
+func (e Errors) Error() string {
+	return strings.Join(e.Pesan(), "; ")
+}
+
+func (e *Errors) Add(field, pesan string) {
+	*e = append(*e, model.FieldError{Field: field, Pesan: pesan})
+}
+
+// Pesan mengubah setiap kesalahan menjadi "field: pesan"
+func (e Errors) Pesan() []string {
+	pesan := make([]string, 0, len(e))
+	for _, fe := range e {
+		pesan = append(pesan, fe.Field+": "+fe.Pesan)
+	}
+	return pesan
+}
+
+// Tempat memeriksa seluruh isi tempat
+func Tempat(t *model.Tempat) (errs Errors) {
+	Teks(&errs, "nama_tempat", t.Nama_Tempat, true, config.NamaTempatMaxLen)
+	Teks(&errs, "lokasi", t.Lokasi, false, config.LokasiMaxLen)
+	Teks(&errs, "fasilitas", t.Fasilitas, false, config.FasilitasMaxLen)
+	Titik(&errs, "", t.Lon, t.Lat)
+	Gambar(&errs, "gambar", t.Gambar)
+	if err := jadwal.Validate(t.JamBuka); err != nil {
+		errs.Add("jam_buka", err.Error())
+	}
+	Kendaraan(&errs, t.Kendaraan)
+	if t.Status != "" && !slices.Contains(config.StatusTempat, t.Status) {
+		errs.Add("status", "harus kosong atau salah satu dari "+strings.Join(config.StatusTempat, ", "))
+	}
+	return
+}
+
+// Koordinat memeriksa format lama {markers: [[lon, lat], ...]}
+func Koordinat(k *model.Koordinat) (errs Errors) {
+	if len(k.Markers) == 0 {
+		errs.Add("markers", "wajib berisi minimal satu pasangan [lon, lat]")
+	}
+	for i, pair := range k.Markers {
+		field := fmt.Sprintf("markers[%d]", i)
+		if len(pair) != 2 {
+			errs.Add(field, "harus berupa pasangan [lon, lat]")
+			continue
+		}
+		Titik(&errs, field+".", pair[0], pair[1])
+	}
+	return
+}
+
+// Teks memeriksa isian wajib dan panjang maksimal dalam karakter
+func Teks(errs *Errors, field, nilai string, wajib bool, maxLen int) {
+	if wajib && strings.TrimSpace(nilai) == "" {
+		errs.Add(field, "wajib diisi")
+		return
+	}
+	if n := utf8.RuneCountInString(nilai); n > maxLen {
+		errs.Add(field, fmt.Sprintf("maksimal %d karakter, terisi %d", maxLen, n))
+	}
+}
+
+// Titik memeriksa rentang lon/lat lalu apakah titik ada di dalam config.AreaLayanan
+func Titik(errs *Errors, prefix string, lon, lat float64) {
+	valid := true
+	if lon < -180 || lon > 180 {
+		errs.Add(prefix+"lon", "harus di antara -180 dan 180")
+		valid = false
+	}
+	if lat < -90 || lat > 90 {
+		errs.Add(prefix+"lat", "harus di antara -90 dan 90")
+		valid = false
+	}
+	if valid && !DalamArea(lon, lat) {
+		errs.Add(prefix+"koordinat", "di luar area layanan")
+	}
+}
+
+// DalamArea true jika area layanan tidak diatur atau titik ada di dalamnya
+func DalamArea(lon, lat float64) bool {
+	return len(config.AreaLayanan) < 3 || geo.DalamPoligon(lon, lat, config.AreaLayanan)
+}
+
+// Gambar memeriksa isian opsional berupa nama file gambar atau URL hasil GambarBaseURL + nama file
+func Gambar(errs *Errors, field, nilai string) {
+	if nilai != "" && !NamaGambar(strings.TrimPrefix(nilai, config.GambarBaseURL)) {
+		errs.Add(field, "harus nama file gambar tanpa folder maupun URL ("+strings.Join(config.GambarExt, ", ")+")")
+	}
+}
+
+// NamaGambar hanya menerima nama file polos berekstensi gambar, tanpa path, URL maupun nama tersembunyi
+func NamaGambar(name string) bool {
+	if name == "" || utf8.RuneCountInString(name) > config.NamaGambarMaxLen {
+		return false
+	}
+	if strings.ContainsAny(name, `/\:?#%`) || strings.HasPrefix(name, ".") || strings.Contains(name, "..") {
+		return false
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+	return slices.Contains(config.GambarExt, strings.ToLower(filepath.Ext(name)))
+}
+
+var namaFolder = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+
+// Folder menerima satu nama folder tujuan upload tanpa pemisah path, titik maupun karakter khusus, kosong berarti root repo
+func Folder(nama string) bool {
+	return nama == "" || namaFolder.MatchString(nama)
+}
+
+// Kendaraan memeriksa jenis kendaraan dan kapasitasnya, field memakai bentuk kendaraan.<jenis>
+func Kendaraan(errs *Errors, kendaraan map[string]model.KendaraanInfo) {
+	jenis := make([]string, 0, len(kendaraan))
+	for j := range kendaraan {
+		jenis = append(jenis, j)
+	}
+	slices.Sort(jenis)
+	for _, j := range jenis {
+		info := kendaraan[j]
+		field := "kendaraan." + j
+		if !slices.Contains(config.JenisKendaraan, j) {
+			errs.Add(field, "jenis kendaraan tidak dikenal, gunakan "+strings.Join(config.JenisKendaraan, ", "))
+			continue
+		}
+		if info.Kapasitas < 0 {
+			errs.Add(field+".kapasitas", "tidak boleh negatif")
+		}
+		if !info.Diizinkan && info.Kapasitas > 0 {
+			errs.Add(field+".kapasitas", "diisi tetapi diizinkan bernilai false")
+		}
+	}
+}
+
+// Ulasan memeriksa rating 1-5, panjang komentar dan nama foto
+func Ulasan(u *model.Ulasan) (errs Errors) {
+	if u.Rating < 1 || u.Rating > 5 {
+		errs.Add("rating", "harus bilangan bulat 1 sampai 5")
+	}
+	Teks(&errs, "komentar", u.Komentar, false, config.KomentarMaxLen)
+	Gambar(&errs, "foto", u.Foto)
+	return
+}
+
+// Laporan memeriksa kategori, panjang komentar dan nama foto
+func Laporan(l *model.Laporan) (errs Errors) {
+	if !slices.Contains(config.KategoriLaporan, l.Kategori) {
+		errs.Add("kategori", "harus salah satu dari "+strings.Join(config.KategoriLaporan, ", "))
+	}
+	Teks(&errs, "komentar", l.Komentar, false, config.KomentarMaxLen)
+	Gambar(&errs, "foto", l.Foto)
+	return
+}
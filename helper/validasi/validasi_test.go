@@ -0,0 +1,90 @@
+package validasi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamaGambar(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"monas.jpg", true},
+		{"Monas Parkir.JPEG", true},
+		{"a.webp", true},
+		{"", false},
+		{"monas", false},
+		{"monas.exe", false},
+		{"monas.jpg.exe", false},
+		{".jpg", false},
+		{".hidden.png", false},
+		{"../monas.jpg", false},
+		{"..jpg", false},
+		{"img/monas.jpg", false},
+		{`img\monas.jpg`, false},
+		{"https://example.com/monas.jpg", false},
+		{"monas.jpg?x=1", false},
+		{"monas%2F.jpg", false},
+		{"mon\x00as.jpg", false},
+		{strings.Repeat("a", 97) + ".jpg", false},
+	}
+	for _, tt := range tests {
+		if got := NamaGambar(tt.name); got != tt.want {
+			t.Errorf("NamaGambar(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTitik(t *testing.T) {
+	tests := []struct {
+		lon, lat float64
+		field    string
+	}{
+		{106.8272, -6.1754, ""},
+		{181, 0, "lon"},
+		{106.8, -91, "lat"},
+		{-74.0060, 40.7128, "koordinat"},
+	}
+	for _, tt := range tests {
+		var errs Errors
+		Titik(&errs, "", tt.lon, tt.lat)
+		if tt.field == "" {
+			if len(errs) > 0 {
+				t.Errorf("Titik(%v, %v) = %v, want valid", tt.lon, tt.lat, errs)
+			}
+			continue
+		}
+		if len(errs) == 0 || errs[0].Field != tt.field {
+			t.Errorf("Titik(%v, %v) = %v, want error di field %s", tt.lon, tt.lat, errs, tt.field)
+		}
+	}
+}
+
+func TestFolder(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"", true},
+		{"img", true},
+		{"tempat_2024-06", true},
+		{strings.Repeat("a", 50), true},
+		{strings.Repeat("a", 51), false},
+		{".", false},
+		{"..", false},
+		{"../x", false},
+		{"a/b", false},
+		{"/img", false},
+		{`a\b`, false},
+		{"a.b", false},
+		{"%2e%2e", false},
+		{"img ", false},
+		{"img\n", false},
+	}
+	for _, tt := range tests {
+		if got := Folder(tt.name); got != tt.want {
+			t.Errorf("Folder(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
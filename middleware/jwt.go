@@ -57,3 +57,9 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// GetAdminID mengambil admin_id yang disimpan AuthMiddleware, string kosong jika request tidak melewati middleware
+func GetAdminID(r *http.Request) string {
+	adminID, _ := r.Context().Value(adminIDKey).(string)
+	return adminID
+}
@@ -1,12 +1,11 @@
 package idname
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/gocroot/helper/atdb"
+	"github.com/gocroot/helper/geocode"
 	"github.com/whatsauth/itmodel"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -18,23 +17,7 @@ func IDName(Pesan itmodel.IteungMessage, db *mongo.Database) (reply string) {
 }
 
 func GetLokasi(mongoconn *mongo.Database, long float64, lat float64) (namalokasi string) {
-	lokasicollection := mongoconn.Collection("lokasi")
-	filter := bson.M{
-		"batas": bson.M{
-			"$geoIntersects": bson.M{
-				"$geometry": bson.M{
-					"type":        "Point",
-					"coordinates": []float64{long, lat},
-				},
-			},
-		},
-	}
-	var lokasi Lokasi
-	err := lokasicollection.FindOne(context.TODO(), filter).Decode(&lokasi)
-	if err != nil {
-		fmt.Printf("GetLokasi: %v\n", err)
-	}
-	return lokasi.Nama
+	return geocode.GetLokasi(mongoconn, long, lat)
 }
 
 func Postdatapresensi(mongoconn *mongo.Database, long float64, lat float64) {
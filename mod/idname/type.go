@@ -1,19 +1,5 @@
 package idname
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
-
-type Lokasi struct { //lokasi yang bisa melakukan presensi
-	ID       primitive.ObjectID `bson:"_id,omitempty"`
-	Nama     string             `bson:"nama,omitempty"`
-	Batas    Geometry           `bson:"batas,omitempty"`
-	Kategori string             `bson:"kategori,omitempty"`
-}
-
-type Geometry struct { //data geometry untuk lokasi presensi
-	Type        string      `json:"type" bson:"type"`
-	Coordinates interface{} `json:"coordinates" bson:"coordinates"`
-}
-
 type DataPresensi struct {
 	Lokasi string `bson:"lokasi"`
 }
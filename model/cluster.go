@@ -0,0 +1,17 @@
+package model
+
+// Cluster adalah kumpulan tempat dalam satu sel grid, Lon/Lat adalah centroid anggotanya.
+// Cluster dengan satu anggota berisi Tempat dan berlaku sebagai titik biasa.
+type Cluster struct {
+	Lon    float64   `json:"lon"`
+	Lat    float64   `json:"lat"`
+	Jumlah int       `json:"jumlah"`
+	BBox   []float64 `json:"bbox,omitempty"` //minLon,minLat,maxLon,maxLat anggota cluster
+	Tempat *Tempat   `json:"tempat,omitempty"`
+}
+
+type ClusterList struct {
+	Zoom      int       `json:"zoom"`
+	Clusters  []Cluster `json:"clusters"`
+	Truncated bool      `json:"truncated"` //true jika cluster dalam bbox melebihi LokasiMaxResult
+}
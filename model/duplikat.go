@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// KandidatDuplikat adalah pasangan tempat yang berdekatan dan namanya mirip
+type KandidatDuplikat struct {
+	A         Tempat  `json:"a"`
+	B         Tempat  `json:"b"`
+	Jarak     float64 `json:"jarak"`     //meter
+	Kemiripan float64 `json:"kemiripan"` //0..1
+}
+
+// MergeTempat adalah catatan penggabungan duplikat ke tempat utama
+type MergeTempat struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UtamaID      primitive.ObjectID `bson:"utama_id" json:"utama_id"`
+	DuplikatID   primitive.ObjectID `bson:"duplikat_id" json:"duplikat_id"`
+	Sebelum      Tempat             `bson:"sebelum" json:"sebelum"`   //tempat utama sebelum digabung
+	Duplikat     Tempat             `bson:"duplikat" json:"duplikat"` //salinan tempat yang dihapus
+	Hasil        Tempat             `bson:"hasil" json:"hasil"`
+	MarkerPindah int64              `bson:"marker_pindah" json:"marker_pindah"`
+	AdminID      string             `bson:"admin_id" json:"admin_id"`
+	Waktu        time.Time          `bson:"waktu" json:"waktu"`
+}
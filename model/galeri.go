@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Foto adalah satu gambar di galeri tempat, urutan slice adalah urutan tampil
+type Foto struct {
+	ID         primitive.ObjectID `bson:"_id" json:"_id"`
+	URL        string             `bson:"url" json:"url"` //varian web
+	Thumb      string             `bson:"thumb,omitempty" json:"thumb,omitempty"`
+	Uploader   string             `bson:"uploader,omitempty" json:"uploader,omitempty"` //admin_id pengunggah, kosong untuk hasil migrasi
+	Caption    string             `bson:"caption,omitempty" json:"caption,omitempty"`
+	UploadedAt time.Time          `bson:"uploaded_at" json:"uploaded_at"`
+	Cover      bool               `bson:"cover,omitempty" json:"cover,omitempty"` //tepat satu foto menjadi cover dan disalin ke Tempat.Gambar
+}
+
+// MigrasiGaleri adalah laporan pemindahan gambar tunggal ke galeri
+type MigrasiGaleri struct {
+	DryRun   bool     `json:"dry_run"`
+	Total    int      `json:"total"`
+	Diproses int      `json:"diproses"`
+	IDs      []string `json:"ids"`
+}
+
+// HasilUpload adalah respon upload gambar, response dan info sama dengan respon lama untuk client yang belum pindah
+type HasilUpload struct {
+	Response string            `json:"response"` //path varian web di repo
+	Info     string            `json:"info,omitempty"`
+	Varian   map[string]string `json:"varian"` //nama varian (web, thumb) ke URL raw
+}
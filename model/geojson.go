@@ -0,0 +1,24 @@
+package model
+
+type FeatureCollection struct {
+	Type       string    `json:"type"`
+	Features   []Feature `json:"features"`
+	Truncated  bool      `json:"truncated,omitempty"` //foreign member, sama dengan TempatList
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+type Feature struct {
+	Type       string      `json:"type"`
+	ID         string      `json:"id,omitempty"`
+	Geometry   *Point      `json:"geometry"`
+	Properties interface{} `json:"properties"`
+}
+
+type TempatProperties struct {
+	Nama_Tempat string  `json:"nama_tempat,omitempty"`
+	Lokasi      string  `json:"lokasi,omitempty"`
+	Fasilitas   string  `json:"fasilitas,omitempty"`
+	Gambar      string  `json:"gambar,omitempty"`
+	Rating      *Rating `json:"rating,omitempty"`
+	Status      string  `json:"status,omitempty"`
+}
@@ -0,0 +1,20 @@
+package model
+
+// ImportRow adalah hasil validasi satu baris CSV, Baris dihitung dari 1 termasuk header
+type ImportRow struct {
+	Baris  int      `json:"baris"`
+	Data   Tempat   `json:"data"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun   bool        `json:"dry_run"`
+	Total    int         `json:"total"`
+	Valid    int         `json:"valid"`
+	Inserted []string    `json:"inserted,omitempty"`
+	Skipped  []ImportRow `json:"skipped,omitempty"`
+	Failed   []ImportRow `json:"failed,omitempty"` //baris valid yang ditolak database saat insert
+	Rows     []ImportRow `json:"rows,omitempty"`   //hanya diisi saat dry run
+	Error    string      `json:"error,omitempty"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Laporan adalah keluhan pengguna tentang kondisi tempat, misalnya sudah tutup atau ada juru parkir liar
+type Laporan struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	TempatID primitive.ObjectID `bson:"tempat_id" json:"tempat_id"`
+	Kategori string             `bson:"kategori" json:"kategori"`
+	Komentar string             `bson:"komentar,omitempty" json:"komentar,omitempty"`
+	Foto     string             `bson:"foto,omitempty" json:"foto,omitempty"`
+	Pelapor  string             `bson:"pelapor,omitempty" json:"-"` //hash nomor telepon atau session, kosong untuk laporan anonim
+	Status   string             `bson:"status" json:"status"`       //open, resolved atau dismissed
+	//diisi admin saat laporan ditutup
+	Catatan      string     `bson:"catatan,omitempty" json:"catatan,omitempty"`
+	DiputuskanBy string     `bson:"diputuskan_by,omitempty" json:"diputuskan_by,omitempty"`
+	DiputuskanAt *time.Time `bson:"diputuskan_at,omitempty" json:"diputuskan_at,omitempty"`
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`
+}
+
+// RingkasanLaporan adalah jumlah laporan yang masih open pada satu tempat
+type RingkasanLaporan struct {
+	Open     int            `bson:"open" json:"open"`
+	Pelapor  int            `bson:"pelapor" json:"pelapor"` //jumlah pelapor berbeda, dibandingkan dengan LaporanAmbang
+	Kategori map[string]int `bson:"kategori" json:"kategori"`
+}
+
+// AntrianLaporan adalah satu tempat beserta laporan open-nya di antrian triase admin
+type AntrianLaporan struct {
+	Tempat  Tempat    `json:"tempat"`
+	Laporan []Laporan `json:"laporan"`
+}
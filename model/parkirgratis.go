@@ -2,8 +2,10 @@ package model
 
 import (
 	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
+
 type Tempat struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
 	Nama_Tempat string             `bson:"nama_tempat,omitempty" json:"nama_tempat,omitempty"`
@@ -11,22 +13,142 @@ type Tempat struct {
 	Fasilitas   string             `bson:"fasilitas,omitempty" json:"fasilitas,omitempty"`
 	Lon         float64            `bson:"lon,omitempty" json:"lon,omitempty"`
 	Lat         float64            `bson:"lat,omitempty" json:"lat,omitempty"`
-	Gambar      string            `bson:"gambar,omitempty" json:"gambar,omitempty"`
+	Gambar      string             `bson:"gambar,omitempty" json:"gambar,omitempty"`     //salinan URL cover galeri untuk client lama
+	Location    *Point             `bson:"location,omitempty" json:"location,omitempty"` //GeoJSON dari lon/lat untuk index 2dsphere
+	//kode dari koleksi fasilitas, Fasilitas tetap dipakai sebagai keterangan bebas
+	FasilitasTags   []string `bson:"fasilitas_tags,omitempty" json:"fasilitas_tags,omitempty"`
+	FasilitasReview []string `bson:"fasilitas_review,omitempty" json:"fasilitas_review,omitempty"` //potongan teks yang gagal dikenali saat migrasi
+	JamBuka         *JamBuka `bson:"jam_buka,omitempty" json:"jam_buka,omitempty"`
+	StatusBuka      string   `bson:"-" json:"status_buka,omitempty"` //dihitung saat dibaca: open, closed, closing_soon atau unknown
+	//key adalah jenis kendaraan (motor, mobil, sepeda), jenis yang tidak tercantum berarti belum diketahui
+	Kendaraan map[string]KendaraanInfo `bson:"kendaraan,omitempty" json:"kendaraan,omitempty"`
+	Wilayah   *Wilayah                 `bson:"wilayah,omitempty" json:"wilayah,omitempty"` //diisi server dari poligon di koleksi lokasi
+	//terisi jika tempat ada di trash, semua endpoint baca mengabaikan tempat ini
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy string     `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+	//naik setiap kali tempat diubah, dikirim sebagai ETag dan dicek lewat If-Match. Dokumen lama tanpa field ini dianggap versi 0
+	Versi int64 `bson:"versi,omitempty" json:"versi"`
+	//ringkasan ulasan yang tidak disembunyikan, dihitung ulang server setiap ada perubahan ulasan
+	Rating *Rating `bson:"rating,omitempty" json:"rating,omitempty"`
+	//kosong berarti aktif, selain itu tutup, berbayar atau parkir_liar yang ditetapkan admin
+	Status string `bson:"status,omitempty" json:"status,omitempty"`
+	//ringkasan laporan open, Ditandai otomatis true saat jumlahnya mencapai config.LaporanAmbang
+	Laporan  *RingkasanLaporan `bson:"laporan,omitempty" json:"laporan,omitempty"`
+	Ditandai bool              `bson:"ditandai,omitempty" json:"ditandai,omitempty"`
+	Galeri   []Foto            `bson:"galeri,omitempty" json:"galeri,omitempty"`
+}
+
+type KendaraanInfo struct {
+	Diizinkan bool `bson:"diizinkan" json:"diizinkan"`
+	Kapasitas int  `bson:"kapasitas,omitempty" json:"kapasitas,omitempty"` //0 berarti kapasitas belum diketahui
+}
+
+// JamBuka adalah jadwal mingguan dalam waktu Asia/Jakarta, Pengecualian menggantikan jadwal mingguan pada tanggal tertentu
+type JamBuka struct {
+	Mingguan     []JadwalHarian       `bson:"mingguan,omitempty" json:"mingguan,omitempty"`
+	Pengecualian []JadwalPengecualian `bson:"pengecualian,omitempty" json:"pengecualian,omitempty"`
+}
+
+// JadwalHarian berlaku untuk Hari 0 (Minggu) sampai 6 (Sabtu) dengan jam format "15:04".
+// Tutup lebih kecil dari Buka berarti melewati tengah malam, "00:00"-"24:00" berarti buka seharian.
+type JadwalHarian struct {
+	Hari  int    `bson:"hari" json:"hari"`
+	Buka  string `bson:"buka" json:"buka"`
+	Tutup string `bson:"tutup" json:"tutup"`
 }
 
+// JadwalPengecualian untuk Tanggal format "2006-01-02", Libur true berarti tutup seharian.
+// Beberapa entri dengan tanggal sama berarti beberapa rentang jam buka pada hari itu.
+type JadwalPengecualian struct {
+	Tanggal    string `bson:"tanggal" json:"tanggal"`
+	Libur      bool   `bson:"libur,omitempty" json:"libur,omitempty"`
+	Buka       string `bson:"buka,omitempty" json:"buka,omitempty"`
+	Tutup      string `bson:"tutup,omitempty" json:"tutup,omitempty"`
+	Keterangan string `bson:"keterangan,omitempty" json:"keterangan,omitempty"`
+}
+
+// FasilitasTag adalah kosakata fasilitas, Alias dipakai untuk mengenali teks bebas
+type FasilitasTag struct {
+	ID    primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Kode  string             `bson:"kode" json:"kode"`
+	Nama  string             `bson:"nama" json:"nama"`
+	Alias []string           `bson:"alias,omitempty" json:"alias,omitempty"`
+}
+
+type MigrasiFasilitas struct {
+	DryRun   bool                   `json:"dry_run"`
+	Total    int                    `json:"total"`
+	Diproses int                    `json:"diproses"`
+	PerluCek int                    `json:"perlu_cek"`
+	Detail   []MigrasiFasilitasItem `json:"detail"`
+}
+
+type MigrasiFasilitasItem struct {
+	ID        string   `json:"_id"`
+	Fasilitas string   `json:"fasilitas"`
+	Tags      []string `json:"fasilitas_tags"`
+	Review    []string `json:"fasilitas_review,omitempty"`
+}
+
+// TempatJarak adalah hasil pencarian tempat terdekat, jarak dalam meter
+type TempatJarak struct {
+	Tempat `bson:",inline"`
+	Jarak  float64 `bson:"jarak" json:"jarak"`
+}
+
+// TempatSearch adalah hasil pencarian teks, Highlight berisi snippet per field yang cocok
+type TempatSearch struct {
+	Tempat    `bson:",inline"`
+	Skor      float64           `bson:"skor" json:"skor"`
+	Highlight map[string]string `bson:"-" json:"highlight,omitempty"`
+}
+
+// TempatList adalah amplop respon daftar tempat yang dibatasi jumlahnya di server
+type TempatList struct {
+	Data       []Tempat `json:"data"`
+	Truncated  bool     `json:"truncated"`
+	NextCursor string   `json:"next_cursor,omitempty"` //kirim sebagai ?after= untuk halaman berikutnya
+}
+
+type Point struct {
+	Type        string    `bson:"type" json:"type"`
+	Coordinates []float64 `bson:"coordinates" json:"coordinates"`
+}
+
+// Koordinat adalah bentuk lama data marker, satu dokumen berisi semua pasangan [lon, lat]
 type Koordinat struct {
-	ID      primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	Markers [][]float64 `json:"markers"`
+	ID         primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Markers    [][]float64        `json:"markers"`
+	MigratedAt *time.Time         `json:"-" bson:"migrated_at,omitempty"`
+}
+
+// Marker adalah satu penanda peta di koleksi penanda, TempatID menautkan ke koleksi tempat
+type Marker struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"_id,omitempty"`
+	TempatID  *primitive.ObjectID `bson:"tempat_id,omitempty" json:"tempat_id,omitempty"`
+	Lon       float64             `bson:"lon" json:"lon"`
+	Lat       float64             `bson:"lat" json:"lat"`
+	Location  *Point              `bson:"location,omitempty" json:"location,omitempty"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+	LegacyIdx *int                `bson:"legacy_idx,omitempty" json:"-"` //posisi di dokumen marker lama, kunci upsert migrasi
 }
-type Admin struct{
+
+type MigrasiMarker struct {
+	Total    int      `json:"total"`
+	Inserted []string `json:"inserted"`
+	Tertaut  int      `json:"tertaut"` //jumlah marker yang berhasil ditautkan ke tempat dengan koordinat sama
+	Skipped  int      `json:"skipped"`
+}
+type Admin struct {
 	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
 	Username string             `bson:"username" json:"username"`
 	Password string             `bson:"password" json:"password"`
 }
 
 type Token struct {
-	ID			string 				`bson:"_id,omitempty" json:"_id,omitempty"`
-	Token		string				`bson:"token" json:"token,omitempty"`
-	AdminID		string				`bson:"admin_id" json:"admin_id,omitempty"`
-	CreatedAt	time.Time			`bson:"created_at" json:"created_at"` 
-}
\ No newline at end of file
+	ID        string    `bson:"_id,omitempty" json:"_id,omitempty"`
+	Token     string    `bson:"token" json:"token,omitempty"`
+	AdminID   string    `bson:"admin_id" json:"admin_id,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Revisi adalah catatan satu perubahan pada dokumen tempat
+type Revisi struct {
+	ID       primitive.ObjectID  `bson:"_id,omitempty" json:"_id,omitempty"`
+	TempatID primitive.ObjectID  `bson:"tempat_id" json:"tempat_id"`
+	Aksi     string              `bson:"aksi" json:"aksi"`                             //create, update, delete, restore, revert, merge atau purge
+	Snapshot *Tempat             `bson:"snapshot,omitempty" json:"snapshot,omitempty"` //isi tempat setelah perubahan, untuk purge isi terakhir sebelum dihapus
+	Diff     []Perubahan         `bson:"diff" json:"diff"`
+	Dari     *primitive.ObjectID `bson:"dari,omitempty" json:"dari,omitempty"` //revisi asal saat revert
+	AdminID  string              `bson:"admin_id,omitempty" json:"admin_id,omitempty"`
+	Waktu    time.Time           `bson:"waktu" json:"waktu"`
+}
+
+// Perubahan adalah nilai lama dan baru satu field, nil berarti field tidak ada
+type Perubahan struct {
+	Field string      `bson:"field" json:"field"`
+	Lama  interface{} `bson:"lama" json:"lama"`
+	Baru  interface{} `bson:"baru" json:"baru"`
+}
+
+// DiffRevisi adalah perbedaan isi tempat antara dua revisi
+type DiffRevisi struct {
+	Dari Revisi      `json:"dari"`
+	Ke   Revisi      `json:"ke"`
+	Diff []Perubahan `json:"diff"`
+}
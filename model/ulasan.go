@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Ulasan adalah penilaian pengguna untuk satu tempat, satu reviewer hanya boleh satu ulasan per tempat
+type Ulasan struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	TempatID primitive.ObjectID `bson:"tempat_id" json:"tempat_id"`
+	Reviewer string             `bson:"reviewer" json:"-"` //hash sha256 dari nomor telepon atau id sesi, tidak pernah dikirim ke client
+	Rating   int                `bson:"rating" json:"rating"`
+	Komentar string             `bson:"komentar,omitempty" json:"komentar,omitempty"`
+	Foto     string             `bson:"foto,omitempty" json:"foto,omitempty"`
+	Hidden   bool               `bson:"hidden,omitempty" json:"hidden,omitempty"`
+	//alasan dan admin yang menyembunyikan ulasan
+	HiddenAlasan string    `bson:"hidden_alasan,omitempty" json:"hidden_alasan,omitempty"`
+	HiddenBy     string    `bson:"hidden_by,omitempty" json:"hidden_by,omitempty"`
+	CreatedAt    time.Time `bson:"created_at" json:"created_at"`
+}
+
+// Rating adalah rata-rata dan jumlah ulasan yang tampil pada satu tempat
+type Rating struct {
+	RataRata float64 `bson:"rata_rata" json:"rata_rata"`
+	Jumlah   int     `bson:"jumlah" json:"jumlah"`
+}
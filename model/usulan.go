@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Usulan adalah tempat kiriman publik yang menunggu moderasi sebelum masuk koleksi tempat
+type Usulan struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"_id,omitempty"`
+	Kode         string              `bson:"kode" json:"kode"`     //kode lacak yang diberikan ke pengirim
+	Status       string              `bson:"status" json:"status"` //pending, approved atau rejected
+	Data         Tempat              `bson:"data" json:"data"`
+	Alasan       string              `bson:"alasan,omitempty" json:"alasan,omitempty"`       //alasan penolakan
+	TempatID     *primitive.ObjectID `bson:"tempat_id,omitempty" json:"tempat_id,omitempty"` //terisi setelah disetujui
+	DiajukanAt   time.Time           `bson:"diajukan_at" json:"diajukan_at"`
+	DiputuskanAt *time.Time          `bson:"diputuskan_at,omitempty" json:"diputuskan_at,omitempty"`
+	ModeratorID  string              `bson:"moderator_id,omitempty" json:"moderator_id,omitempty"`
+}
+
+// StatusUsulan adalah informasi yang boleh dilihat pengirim lewat kode lacak
+type StatusUsulan struct {
+	Kode         string              `json:"kode"`
+	Status       string              `json:"status"`
+	NamaTempat   string              `json:"nama_tempat"`
+	Alasan       string              `json:"alasan,omitempty"`
+	TempatID     *primitive.ObjectID `json:"tempat_id,omitempty"`
+	DiajukanAt   time.Time           `json:"diajukan_at"`
+	DiputuskanAt *time.Time          `json:"diputuskan_at,omitempty"`
+}
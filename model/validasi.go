@@ -0,0 +1,13 @@
+package model
+
+// FieldError adalah satu kesalahan validasi, Field memakai nama JSON seperti "lon" atau "markers[2]"
+type FieldError struct {
+	Field string `json:"field"`
+	Pesan string `json:"pesan"`
+}
+
+// ValidasiGagal adalah body respon 422, semua kesalahan dikirim sekaligus
+type ValidasiGagal struct {
+	Response string       `json:"response"`
+	Errors   []FieldError `json:"errors"`
+}
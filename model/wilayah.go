@@ -0,0 +1,38 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Lokasi adalah poligon di koleksi lokasi, dipakai presensi dan reverse geocoding wilayah administrasi
+type Lokasi struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Nama     string             `bson:"nama,omitempty" json:"nama,omitempty"`
+	Batas    Geometry           `bson:"batas,omitempty" json:"batas,omitempty"`
+	Kategori string             `bson:"kategori,omitempty" json:"kategori,omitempty"`
+}
+
+// Geometry adalah geometry GeoJSON, Coordinates mengikuti Type (Polygon, MultiPolygon, dst)
+type Geometry struct {
+	Type        string      `json:"type" bson:"type"`
+	Coordinates interface{} `json:"coordinates" bson:"coordinates"`
+}
+
+// Wilayah adalah hasil reverse geocoding koordinat tempat ke poligon administrasi
+type Wilayah struct {
+	Kelurahan string `bson:"kelurahan,omitempty" json:"kelurahan,omitempty"`
+	Kecamatan string `bson:"kecamatan,omitempty" json:"kecamatan,omitempty"`
+	Kota      string `bson:"kota,omitempty" json:"kota,omitempty"`
+}
+
+type ImportWilayah struct {
+	Total    int      `json:"total"`
+	Disimpan int      `json:"disimpan"`
+	Skipped  []string `json:"skipped,omitempty"`
+}
+
+// WilayahStat adalah jumlah tempat per wilayah administrasi
+type WilayahStat struct {
+	ID       primitive.ObjectID `bson:"_id" json:"_id"`
+	Nama     string             `bson:"nama" json:"nama"`
+	Kategori string             `bson:"kategori" json:"kategori"`
+	Jumlah   int                `bson:"-" json:"jumlah"`
+}
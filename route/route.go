@@ -7,6 +7,7 @@ import (
 	"github.com/gocroot/controller"
 	"github.com/gocroot/handler"
 	"github.com/gocroot/helper"
+	"github.com/gocroot/middleware"
 )
 
 func URL(w http.ResponseWriter, r *http.Request) {
@@ -14,33 +15,129 @@ func URL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	config.SetEnv()
+	config.EnsureIndexes()
+	controller.MigrasiMarkerLama()
 
 	var method, path string = r.Method, r.URL.Path
 	switch {
 	case method == "GET" && path == "/":
 		controller.GetHome(w, r)
-	case method == "GET" && path == "/data/lokasi":
+	case method == "GET" && (path == "/data/lokasi" || path == "/data/lokasi.geojson"):
 		controller.GetLokasi(w, r)
-	case method == "GET" && path == "/data/marker":
+	case method == "GET" && path == "/data/lokasi/nearby":
+		controller.GetLokasiNearby(w, r)
+	case method == "GET" && path == "/data/lokasi/search":
+		controller.GetLokasiSearch(w, r)
+	case method == "GET" && (path == "/data/marker" || path == "/data/marker.geojson"):
 		controller.GetMarker(w, r)
 	case method == "POST" && helper.URLParam(path, "/webhook/nomor/:nomorwa"):
 		controller.PostInboxNomor(w, r)
+	case method == "GET" && path == "/data/cluster":
+		controller.GetCluster(w, r)
+	case method == "GET" && path == "/data/markers":
+		controller.GetMarkers(w, r)
+	case method == "GET" && helper.URLParam(path, "/data/marker/:id"):
+		controller.GetMarkerByID(w, r)
+	case method == "POST" && path == "/data/marker":
+		controller.PostMarker(w, r)
+	case method == "PUT" && helper.URLParam(path, "/data/marker/:id"):
+		controller.PutMarker(w, r)
+	case method == "DELETE" && helper.URLParam(path, "/data/marker/:id"):
+		controller.DeleteMarker(w, r)
 	case method == "POST" && path == "/tempat-parkir":
 		controller.PostTempatParkir(w, r)
+	case method == "GET" && helper.URLParam(path, "/tempat-parkir/:kode"):
+		controller.GetStatusUsulan(w, r)
 	case method == "POST" && path == "/koordinat":
 		controller.PostKoordinat(w, r)
 	case method == "POST" && helper.URLParam(path, "/upload/:path"):
 		controller.PostUploadGithub(w, r)
+	case method == "GET" && helper.URLParam(path, "/data/tempat/:id"):
+		controller.GetTempatByID(w, r)
+	case method == "PATCH" && helper.URLParam(path, "/data/tempat/:id"):
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PatchTempat)).ServeHTTP(w, r)
 	case method == "PUT" && path == "/data/tempat":
-		controller.PutTempatParkir(w, r)
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PutTempatParkir)).ServeHTTP(w, r)
 	case method == "PUT" && path == "/data/koordinat":
 		controller.PutKoordinat(w, r)
 	case method == "DELETE" && path == "/data/tempat":
-		controller.DeleteTempatParkir(w, r)
+		middleware.AuthMiddleware(http.HandlerFunc(controller.DeleteTempatParkir)).ServeHTTP(w, r)
 	case method == "DELETE" && path == "/data/koordinat":
 		controller.DeleteKoordinat(w, r)
 	case method == "POST" && path == "/admin/login":
 		handler.Login(w, r)
+	case method == "POST" && path == "/admin/import/tempat":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostImportTempat)).ServeHTTP(w, r)
+	case method == "GET" && path == "/data/wilayah":
+		controller.GetWilayah(w, r)
+	case method == "GET" && path == "/data/ulasan":
+		controller.GetUlasan(w, r)
+	case method == "POST" && path == "/data/ulasan":
+		controller.PostUlasan(w, r)
+	case method == "POST" && path == "/data/laporan":
+		controller.PostLaporan(w, r)
+	case method == "GET" && path == "/data/fasilitas":
+		controller.GetFasilitas(w, r)
+	case method == "POST" && path == "/admin/fasilitas":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostFasilitas)).ServeHTTP(w, r)
+	case method == "DELETE" && path == "/admin/fasilitas":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.DeleteFasilitas)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/migrasi/fasilitas":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostMigrasiFasilitas)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/migrasi/marker":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostMigrasiMarker)).ServeHTTP(w, r)
+	case method == "GET" && path == "/admin/duplikat":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.GetDuplikat)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/duplikat/merge":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostMergeDuplikat)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/wilayah/import":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostImportWilayah)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/wilayah/geocode":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostGeocodeTempat)).ServeHTTP(w, r)
+	case method == "GET" && path == "/admin/tempat/trash":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.GetTrashTempat)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/tempat/restore":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostRestoreTempat)).ServeHTTP(w, r)
+	case method == "DELETE" && path == "/admin/tempat/purge":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.DeletePurgeTempat)).ServeHTTP(w, r)
+	case method == "GET" && path == "/admin/revisi":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.GetRevisi)).ServeHTTP(w, r)
+	case method == "GET" && path == "/admin/revisi/diff":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.GetDiffRevisi)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/revisi/revert":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostRevertRevisi)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/tempat":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostTempatAdmin)).ServeHTTP(w, r)
+	case method == "GET" && path == "/admin/usulan":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.GetUsulan)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/usulan/approve":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostApproveUsulan)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/usulan/reject":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostRejectUsulan)).ServeHTTP(w, r)
+	case method == "GET" && path == "/admin/ulasan":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.GetUlasanAdmin)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/ulasan/hide":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostHideUlasan)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/ulasan/unhide":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostUnhideUlasan)).ServeHTTP(w, r)
+	case method == "GET" && path == "/admin/laporan":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.GetAntrianLaporan)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/laporan/resolve":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostResolveLaporan)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/laporan/dismiss":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostDismissLaporan)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/laporan/terapkan":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostTerapkanLaporan)).ServeHTTP(w, r)
+	case method == "POST" && path == "/admin/migrasi/galeri":
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostMigrasiGaleri)).ServeHTTP(w, r)
+	case method == "PUT" && helper.URLParam(path, "/admin/galeri/urutan/:id"):
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PutUrutanGaleri)).ServeHTTP(w, r)
+	case method == "PUT" && helper.URLParam(path, "/admin/galeri/cover/:id"):
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PutCoverGaleri)).ServeHTTP(w, r)
+	case method == "POST" && helper.URLParam(path, "/admin/galeri/:id"):
+		middleware.AuthMiddleware(http.HandlerFunc(controller.PostFotoGaleri)).ServeHTTP(w, r)
+	case method == "DELETE" && helper.URLParam(path, "/admin/galeri/:id"):
+		middleware.AuthMiddleware(http.HandlerFunc(controller.DeleteFotoGaleri)).ServeHTTP(w, r)
 	default:
 		controller.NotFound(w, r)
 	}